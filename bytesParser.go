@@ -0,0 +1,120 @@
+package bencode
+
+import (
+	"bytes"
+	"io"
+	"unsafe"
+)
+
+// A bencode parser that uses a byte slice as source
+type bytesParser struct {
+	bencode []byte
+	i       int
+	// Return strings and slices that share memory with bencode
+	zeroCopy bool
+}
+
+// Returns the next byte
+func (bp *bytesParser) readByte() (byte, error) {
+	if bp.i >= len(bp.bencode) {
+		return 0, io.EOF
+	}
+	bp.i++
+	return bp.bencode[bp.i-1], nil
+}
+
+// Backtracks by 1 byte
+//
+// MUST only be called at most once immediately following a call to nextByte()
+func (bp *bytesParser) undoReadByte() {
+	bp.i--
+	if bp.i < 0 {
+		panic("bytesParser: invalid undoReadByte")
+	}
+}
+
+// Returns the bytes as a string, without copying them in zero-copy mode
+func (bp *bytesParser) toString(b []byte) string {
+	if bp.zeroCopy && len(b) > 0 {
+		return unsafe.String(&b[0], len(b))
+	}
+	return string(b)
+}
+
+// Reads a number until an end byte
+func (bp *bytesParser) readNumberTo(separator byte) (string, error) {
+	i := bp.i
+	if i >= len(bp.bencode) {
+		return "", io.ErrUnexpectedEOF
+	}
+	index := bytes.IndexByte(bp.bencode[i:], separator)
+	if index == -1 {
+		return "", io.ErrUnexpectedEOF
+	}
+	bp.i += index + 1
+	return bp.toString(bp.bencode[i : i+index]), nil
+}
+
+// Returns the next bytes of a given length without copying them
+func (bp *bytesParser) next(length int) ([]byte, error) {
+	// Check the length before moving, as a huge length could overflow the index
+	if length > len(bp.bencode)-bp.i {
+		bp.i = len(bp.bencode)
+		return nil, io.ErrUnexpectedEOF
+	}
+	bp.i += length
+	// Limit the capacity so that appending to the slice can't overwrite the input
+	return bp.bencode[bp.i-length : bp.i : bp.i], nil
+}
+
+// Returns a string of a given length
+func (bp *bytesParser) readString(length int) (string, error) {
+	b, err := bp.next(length)
+	if err != nil {
+		return "", err
+	}
+	return bp.toString(b), nil
+}
+
+// Returns a slice with the bytes of a given length, which is a sub-slice
+// of the input in zero-copy mode
+func (bp *bytesParser) readBytes(length int) ([]byte, error) {
+	b, err := bp.next(length)
+	if err != nil || bp.zeroCopy {
+		return b, err
+	}
+	return bytes.Clone(b), nil
+}
+
+// Skips a given number of bytes without allocating them
+func (bp *bytesParser) discard(length int) error {
+	_, err := bp.next(length)
+	return err
+}
+
+// Returns the number of bytes read so far
+func (bp *bytesParser) offset() int64 {
+	return int64(bp.i)
+}
+
+// Returns the rest of the slice
+func (bp *bytesParser) unread() io.Reader {
+	return bytes.NewReader(bp.bencode[bp.i:])
+}
+
+// Returns the rest of the slice
+func (bp *bytesParser) remaining() io.Reader {
+	return bp.unread()
+}
+
+// Returns a bencode decoder from a given byte slice.
+// The slice is not copied and must not be modified while decoding.
+func NewParserFromBytes(bencode []byte, opts ...DecoderOption) *Decoder {
+	bp := &bytesParser{
+		bencode: bencode,
+		i:       0,
+	}
+	d := newDecoder(bp, opts)
+	bp.zeroCopy = d.options.ZeroCopy
+	return d
+}
@@ -0,0 +1,117 @@
+package bencode_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+// Checks that the bytes backend decodes like the string backend
+func BytesParserTestHelper(t *testing.T, testCase string, opts ...bencode.DecoderOption) {
+	expected, expectedErr := bencode.NewParserFromString(testCase).AsInterface()
+	actual, err := bencode.NewParserFromBytes([]byte(testCase), opts...).AsInterface()
+	if (err == nil) != (expectedErr == nil) || !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected (%v, %v) for %q, got (%v, %v)", expected, expectedErr, testCase, actual, err)
+	}
+	var raw bencode.RawMessage
+	err = bencode.NewParserFromBytes([]byte(testCase), opts...).Decode(&raw)
+	if (err == nil) != (expectedErr == nil) || err == nil && string(raw) != testCase {
+		t.Fatalf("Expected raw %q, got (%q, %v)", testCase, raw, err)
+	}
+}
+
+func TestBytesParser(t *testing.T) {
+	for _, opts := range [][]bencode.DecoderOption{nil, {bencode.WithZeroCopy()}} {
+		for _, actual := range stringsTestCases {
+			BytesParserTestHelper(t, fmt.Sprintf("%d:%s", len(actual), actual), opts...)
+		}
+		for test := range intsTestCases {
+			BytesParserTestHelper(t, test, opts...)
+		}
+		for test := range slicesTestCases {
+			BytesParserTestHelper(t, test, opts...)
+		}
+		for test := range complexMapTestCases {
+			BytesParserTestHelper(t, test, opts...)
+		}
+		for _, invalid := range invalidParserInputs {
+			BytesParserTestHelper(t, invalid, opts...)
+		}
+	}
+}
+
+func TestZeroCopy(t *testing.T) {
+	data := []byte("l5:hello5:worlde")
+	// By default the values are copies of the input
+	list, err := bencode.NewParserFromBytes(data, bencode.WithByteStrings()).AsList()
+	if err != nil {
+		t.Fatalf("AsList returned error: %v", err)
+	}
+	copy(data[3:], "HELLO")
+	if string(list[0].([]byte)) != "hello" {
+		t.Fatalf("Expected a copy of the input, got %q", list[0])
+	}
+	// In zero-copy mode they share memory with the input
+	d := bencode.NewParserFromBytes(data, bencode.WithZeroCopy(), bencode.WithByteStrings())
+	if list, err = d.AsList(); err != nil {
+		t.Fatalf("AsList returned error: %v", err)
+	}
+	copy(data[3:], "howdy")
+	hello := list[0].([]byte)
+	if string(hello) != "howdy" || cap(hello) != len(hello) {
+		t.Fatalf("Expected a sub-slice of the input, got %q with capacity %d", hello, cap(hello))
+	}
+	// Appending never overwrites the input
+	_ = append(hello, '!')
+	if string(data) != "l5:howdy5:worlde" {
+		t.Fatalf("Expected the input to be unchanged, got %q", data)
+	}
+	var s struct {
+		Name string `bencode:"name"`
+	}
+	data = []byte("d4:name5:alicee")
+	if err := bencode.NewParserFromBytes(data, bencode.WithZeroCopy()).Decode(&s); err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	copy(data[9:], "A")
+	if s.Name != "Alice" {
+		t.Fatalf("Expected the string to share memory with the input, got %q", s.Name)
+	}
+}
+
+func BenchmarkBytesParser(b *testing.B) {
+	for benchName, testString := range parserBenchmarks {
+		data := []byte(testString)
+		b.Run(benchName, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				bencode.NewParserFromBytes(data).AsInterface()
+			}
+		})
+		b.Run(benchName+"ZeroCopy", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				bencode.NewParserFromBytes(data, bencode.WithZeroCopy()).AsInterface()
+			}
+		})
+	}
+	b.Run("torrentStringAsString", func(b *testing.B) {
+		data := []byte(fedoraMagnetParsed)
+		for i := 0; i < b.N; i++ {
+			bencode.NewParserFromBytes(data).AsString()
+		}
+	})
+}
+
+func FuzzBytesParser(f *testing.F) {
+	for test := range complexMapTestCases {
+		f.Add([]byte(test))
+	}
+	for _, invalid := range invalidParserInputs {
+		f.Add([]byte(invalid))
+	}
+	f.Fuzz(func(t *testing.T, test []byte) {
+		BytesParserTestHelper(t, string(test))
+		BytesParserTestHelper(t, string(test), bencode.WithZeroCopy())
+	})
+}
@@ -0,0 +1,234 @@
+// Command bencode inspects, validates, converts and edits bencode documents
+// such as .torrent files and tracker responses.
+//
+// Usage:
+//
+//	bencode dump [-max-length n] [file]
+//	bencode validate [-strict] [file]
+//	bencode to-json [file]
+//	bencode from-json [file]
+//	bencode get [-json] <path> [file]
+//	bencode set <path> <json value> [file]
+//	bencode infohash [file]
+//
+// Every command reads from the file if given, or from the standard input
+// otherwise or if the file is "-", and writes to the standard output.
+// Paths select a value such as "info.name" or "announce-list[0][0]".
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/stefanovazzocell/bencode"
+	"github.com/stefanovazzocell/bencode/metainfo"
+)
+
+const usage = `Usage: bencode <command> [flags] [arguments] [file]
+
+Commands:
+  dump [-max-length n] [file]            print an indented tree
+  validate [-strict] [file]              check that the input is valid bencode
+  to-json [file]                         convert bencode to JSON
+  from-json [file]                       convert JSON to bencode
+  get [-json] <path> [file]              print the value at a path such as "info.name"
+  set <path> <json value> [file]         print the input with the value at a path replaced
+  infohash [file]                        print the info-hashes of a .torrent file
+
+The input is the file if given, or the standard input otherwise.
+`
+
+// Error for invalid command-line arguments, which exit with status 2
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Runs a command and returns the exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	commands := map[string]func(args []string, stdin io.Reader, stdout io.Writer) error{
+		"dump":      dump,
+		"validate":  validate,
+		"to-json":   toJSON,
+		"from-json": fromJSON,
+		"get":       get,
+		"set":       set,
+		"infohash":  infohash,
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "bencode: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	if err := command(args[1:], stdin, stdout); errors.Is(err, errUsage) {
+		fmt.Fprint(stderr, usage)
+		return 2
+	} else if err != nil {
+		fmt.Fprintf(stderr, "bencode %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// Returns the flags of a command, which don't print anything on errors
+func newFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// Parses the flags of a command and returns the input and the positional
+// arguments before the file
+func parseArgs(flags *flag.FlagSet, args []string, stdin io.Reader, positional int) (io.ReadCloser, []string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, errUsage
+	}
+	args = flags.Args()
+	if len(args) < positional || len(args) > positional+1 {
+		return nil, nil, errUsage
+	}
+	if len(args) == positional || args[positional] == "-" {
+		return io.NopCloser(stdin), args[:positional], nil
+	}
+	file, err := os.Open(args[positional])
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, args[:positional], nil
+}
+
+// Prints an indented tree
+func dump(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := newFlags("dump")
+	maxLength := flags.Int("max-length", 64, "the number of bytes shown of longer strings, 0 to show them in full")
+	input, _, err := parseArgs(flags, args, stdin, 0)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	return bencode.Dump(stdout, input, bencode.WithDumpMaxLength(*maxLength))
+}
+
+// Checks that the input is a single valid value
+func validate(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := newFlags("validate")
+	strict := flags.Bool("strict", false, "also require the canonical form")
+	input, _, err := parseArgs(flags, args, stdin, 0)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	opts := []bencode.DecoderOption{bencode.WithDisallowTrailingData()}
+	if *strict {
+		opts = append(opts, bencode.WithStrict())
+	}
+	if err := bencode.NewDecoder(input, opts...).Skip(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, "valid")
+	return err
+}
+
+// Converts bencode to JSON
+func toJSON(args []string, stdin io.Reader, stdout io.Writer) error {
+	input, _, err := parseArgs(newFlags("to-json"), args, stdin, 0)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	return bencode.ToJSON(input, stdout)
+}
+
+// Converts JSON to bencode
+func fromJSON(args []string, stdin io.Reader, stdout io.Writer) error {
+	input, _, err := parseArgs(newFlags("from-json"), args, stdin, 0)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	return bencode.FromJSON(input, stdout)
+}
+
+// Prints the value at a path as a tree or as JSON
+func get(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := newFlags("get")
+	asJSON := flags.Bool("json", false, "print the value as JSON")
+	input, positional, err := parseArgs(flags, args, stdin, 1)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	raw, err := bencode.GetPath(data, positional[0])
+	if err != nil {
+		return err
+	}
+	value := bytes.NewReader(raw)
+	if *asJSON {
+		return bencode.ToJSON(value, stdout)
+	}
+	return bencode.Dump(stdout, value)
+}
+
+// Prints the input with the value at a path replaced or added
+func set(args []string, stdin io.Reader, stdout io.Writer) error {
+	input, positional, err := parseArgs(newFlags("set"), args, stdin, 2)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	path, err := bencode.ParsePath(positional[0])
+	if err != nil {
+		return err
+	}
+	var value bytes.Buffer
+	if err := bencode.FromJSON(bytes.NewReader([]byte(positional[1])), &value); err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+	output, err := replace(data, path, value.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(output)
+	return err
+}
+
+// Prints the v1 and v2 info-hashes of a torrent
+func infohash(args []string, stdin io.Reader, stdout io.Writer) error {
+	input, _, err := parseArgs(newFlags("infohash"), args, stdin, 0)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	mi, err := metainfo.Load(input)
+	if err != nil {
+		return err
+	}
+	info, err := mi.Info()
+	if err != nil {
+		return err
+	}
+	if info.HasV1() || !info.HasV2() {
+		fmt.Fprintln(stdout, "v1", mi.InfoHash())
+	}
+	if info.HasV2() {
+		fmt.Fprintln(stdout, "v2", mi.InfoHashV2())
+	}
+	return nil
+}
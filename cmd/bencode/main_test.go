@@ -0,0 +1,100 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testInfo    = "d6:lengthi1e4:name5:a.iso12:piece lengthi16384e6:pieces20:" + "aaaaaaaaaaaaaaaaaaaa" + "e"
+	testTorrent = "d8:announce14:http://tracker13:announce-listll14:http://trackerel9:http://b/ee4:info" + testInfo + "e"
+)
+
+// Runs a command with some standard input and returns its output and status
+func runCommand(stdin string, args ...string) (string, string, int) {
+	var stdout, stderr strings.Builder
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), status
+}
+
+func TestCommands(t *testing.T) {
+	hash := sha1.Sum([]byte(testInfo))
+	testCases := []struct {
+		stdin    string
+		args     []string
+		expected string
+	}{
+		{"d1:ai1ee", []string{"dump"}, "{\n  \"a\": 1\n}\n"},
+		{"6:\x00\x01\x02\x03\x04\x05", []string{"dump", "-max-length", "2"}, "<0001>... (6 bytes)\n"},
+		{"d1:ai1ee", []string{"validate"}, "valid\n"},
+		{"d1:bi1e1:ai1ee", []string{"validate"}, "valid\n"},
+		{"d1:ai1ee", []string{"validate", "--strict"}, "valid\n"},
+		{"d1:a3:\xff\x00\x01e", []string{"to-json"}, "{\"a\":{\"$bytes\":\"/wAB\"}}\n"},
+		{`{"a":{"$bytes":"/wAB"}}`, []string{"from-json"}, "d1:a3:\xff\x00\x01e"},
+		{testTorrent, []string{"get", "info.name"}, "\"a.iso\"\n"},
+		{testTorrent, []string{"get", "announce-list[1][0]"}, "\"http://b/\"\n"},
+		{testTorrent, []string{"get", "-json", "announce-list"}, "[[\"http://tracker\"],[\"http://b/\"]]\n"},
+		{"d1:ai1e1:ci3ee", []string{"set", "a", "2"}, "d1:ai2e1:ci3ee"},
+		{"d1:ai1e1:ci3ee", []string{"set", "b", `"x"`}, "d1:ai1e1:b1:x1:ci3ee"},
+		{"d1:ai1e1:ci3ee", []string{"set", "d", `[]`}, "d1:ai1e1:ci3e1:dlee"},
+		{"d1:ali1eee", []string{"set", "a[1]", `{"b":2}`}, "d1:ali1ed1:bi2eeee"},
+		{"d1:ali1eee", []string{"set", "a[0]", `3`}, "d1:ali3eee"},
+		{"li1ee", []string{"set", "", `0`}, "i0e"},
+		{testTorrent, []string{"infohash"}, "v1 " + hex.EncodeToString(hash[:]) + "\n"},
+	}
+	for _, test := range testCases {
+		stdout, stderr, status := runCommand(test.stdin, test.args...)
+		if status != 0 || stdout != test.expected {
+			t.Fatalf("Expected %q for %v, got %q with status %d (%s)", test.expected, test.args, stdout, status, stderr)
+		}
+	}
+	// Files are read instead of the standard input
+	file := filepath.Join(t.TempDir(), "test.torrent")
+	if err := os.WriteFile(file, []byte(testTorrent), 0o600); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	if stdout, stderr, status := runCommand("", "get", "info.piece length", file); status != 0 || stdout != "16384\n" {
+		t.Fatalf("Expected 16384, got %q with status %d (%s)", stdout, status, stderr)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	testCases := []struct {
+		stdin  string
+		args   []string
+		status int
+	}{
+		{"", []string{}, 2},
+		{"", []string{"unknown"}, 2},
+		{"", []string{"dump", "-unknown"}, 2},
+		{"", []string{"dump", "a", "b"}, 2},
+		{"", []string{"get"}, 2},
+		{"", []string{"set", "a"}, 2},
+		{"", []string{"dump", filepath.Join(t.TempDir(), "missing")}, 1},
+		{"li1e", []string{"dump"}, 1},
+		{"i1ei2e", []string{"validate"}, 1},
+		{"d1:bi1e1:ai1ee", []string{"validate", "-strict"}, 1},
+		{"i03e", []string{"validate", "-strict"}, 1},
+		{"true", []string{"from-json"}, 1},
+		{"1 garbage", []string{"from-json"}, 1},
+		{"d1:ai1ee", []string{"set", "a", "1 2"}, 1},
+		{"d1:ai1ee", []string{"get", "b"}, 1},
+		{"d1:ai1ee", []string{"get", "a.b"}, 1},
+		{"d1:ai1ee", []string{"get", "a[x]"}, 1},
+		{"d1:ali1eee", []string{"get", "a[0]b"}, 1},
+		{"d1:ali1eee", []string{"set", "a.01", "2"}, 1},
+		{"d1:ali1eee", []string{"set", "a[2]", "1"}, 1},
+		{"d1:ai1ee", []string{"set", "b.c", "1"}, 1},
+		{"d1:ai1ee", []string{"set", "a", "1.5"}, 1},
+		{"d1:ai1ee", []string{"infohash"}, 1},
+	}
+	for _, test := range testCases {
+		if stdout, _, status := runCommand(test.stdin, test.args...); status != test.status {
+			t.Fatalf("Expected status %d for %v, got %d (%q)", test.status, test.args, status, stdout)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+// The location of the value at a path in a document
+type location struct {
+	// True if the value exists
+	found bool
+	// The offsets of the first byte and after the last byte of the value
+	start, end int64
+	// If the value doesn't exist but its parent does, the offset where it
+	// can be inserted: before the first greater key of a dictionary or at
+	// the end of a list that is as long as the index; -1 otherwise
+	insert int64
+	// True if the parent of the value is a dictionary
+	dict bool
+}
+
+// Returns the location of the value at a path, or where it can be added
+func find(data []byte, path []string) (location, error) {
+	d := bencode.NewParserFromBytes(data)
+	for i, key := range path {
+		loc, err := seek(d, key)
+		if err != nil {
+			return location{}, err
+		}
+		if !loc.found {
+			if i < len(path)-1 {
+				loc.insert = -1
+			}
+			return loc, nil
+		}
+	}
+	start := d.InputOffset()
+	if err := d.Skip(); err != nil {
+		return location{}, err
+	}
+	return location{found: true, start: start, end: d.InputOffset(), insert: -1}, nil
+}
+
+// Reads the start of a list or dictionary up to the value of a key or index
+func seek(d *bencode.Decoder, key string) (location, error) {
+	token, err := d.Token()
+	if err != nil {
+		return location{}, err
+	}
+	switch token.Type {
+	case bencode.DictStart:
+		loc := location{insert: -1, dict: true}
+		for {
+			offset := d.InputOffset()
+			if next, err := d.Next(); err != nil {
+				return location{}, err
+			} else if next == bencode.End {
+				if loc.insert == -1 {
+					loc.insert = offset
+				}
+				return loc, nil
+			}
+			k, err := d.Token()
+			if err != nil {
+				return location{}, err
+			}
+			if k.String == key {
+				return location{found: true}, nil
+			}
+			// Keep looking for the key in case the keys are not sorted
+			if k.String > key && loc.insert == -1 {
+				loc.insert = offset
+			}
+			if err := d.Skip(); err != nil {
+				return location{}, err
+			}
+		}
+	case bencode.ListStart:
+		index, ok := bencode.ParseIndex(key)
+		if !ok {
+			return location{insert: -1}, nil
+		}
+		for i := 0; ; i++ {
+			offset := d.InputOffset()
+			if next, err := d.Next(); err != nil {
+				return location{}, err
+			} else if next == bencode.End {
+				if i == index {
+					return location{insert: offset}, nil
+				}
+				return location{insert: -1}, nil
+			}
+			if i == index {
+				return location{found: true}, nil
+			}
+			if err := d.Skip(); err != nil {
+				return location{}, err
+			}
+		}
+	}
+	// Integers and strings contain no values
+	return location{insert: -1}, nil
+}
+
+// Returns a copy of the data with the value at a path replaced, or added
+// if its parent exists. The rest of the data is kept as is.
+func replace(data []byte, path []string, value []byte) ([]byte, error) {
+	loc, err := find(data, path)
+	if err != nil {
+		return nil, err
+	}
+	start, end := loc.start, loc.end
+	if !loc.found {
+		if loc.insert == -1 {
+			return nil, bencode.ErrPathNotFound
+		}
+		start, end = loc.insert, loc.insert
+		if loc.dict {
+			key := path[len(path)-1]
+			value = append([]byte(strconv.Itoa(len(key))+":"+key), value...)
+		}
+	}
+	output := make([]byte, 0, len(data)-int(end-start)+len(value))
+	output = append(output, data[:start]...)
+	output = append(output, value...)
+	return append(output, data[end:]...), nil
+}
@@ -1,6 +1,12 @@
 package bencode
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+)
 
 var (
 	// Error for when the user tries to read a specific type of bencode
@@ -8,6 +14,33 @@ var (
 	ErrInvalidType = errors.New("invalid bencode output type")
 	// Error indicating an invalid string length such as "-1:"
 	ErrInvalidStringLen = errors.New("invalid bencode: string length can't be negative")
+	// Error in strict mode for a number with leading zeros such as "i03e" or "05:hello"
+	ErrLeadingZero = errors.New("invalid bencode: numbers can't have leading zeros")
+	// Error in strict mode for a negative zero such as "i-0e"
+	ErrNegativeZero = errors.New("invalid bencode: numbers can't be negative zero")
+	// Error in strict mode for a number with an explicit sign such as "i+5e"
+	ErrPlusSign = errors.New("invalid bencode: numbers can't have a plus sign")
+	// Error in strict mode for dictionary keys that are not sorted
+	ErrUnsortedKeys = errors.New("invalid bencode: dictionary keys must be sorted")
+	// Error in strict mode for a dictionary key that appears more than once
+	ErrDuplicateKey = errors.New("invalid bencode: dictionary keys must be unique")
+	// Error for lists and dictionaries nested deeper than the maximum depth
+	ErrMaxDepth = errors.New("invalid bencode: maximum nesting depth exceeded")
+	// Error for a string longer than the maximum string length
+	ErrLargeStringLen = errors.New("invalid bencode: string length exceeds the maximum string length")
+	// Error for strings that together exceed the maximum total bytes of a decoder
+	ErrMaxTotalBytes = errors.New("invalid bencode: strings exceed the maximum total bytes")
+	// Error for data after the top-level value when trailing data is disallowed
+	ErrTrailingData = errors.New("invalid bencode: data after the top-level value")
+)
+
+const (
+	// The default maximum nesting depth of lists and dictionaries
+	DefaultMaxDepth = 512
+	// The default maximum length of a single string
+	DefaultMaxStringLength = 2 << 22 // ~ 8MB
+	// Deprecated: use DefaultMaxStringLength or WithMaxStringLength instead
+	MaxStringLength = DefaultMaxStringLength
 )
 
 // A generic bencode reader interface
@@ -18,46 +51,575 @@ type bencodeReader interface {
 	//
 	// MUST only be called at most once immediately following a call to nextByte()
 	undoReadByte()
-	// Reads a number until an end byte
-	readIntTo(separator byte) (int, error)
+	// Reads a number until an end byte and returns it unparsed
+	readNumberTo(separator byte) (string, error)
 	// Returns a string of a given length
 	readString(length int) (string, error)
+	// Returns a new slice with the bytes of a given length
+	readBytes(length int) ([]byte, error)
+	// Skips a given number of bytes without allocating them
+	discard(length int) error
+	// Returns the number of bytes read so far
+	offset() int64
+	// Returns the bytes read ahead but not consumed yet
+	unread() io.Reader
+	// Returns all the bytes not consumed yet
+	remaining() io.Reader
+}
+
+// The options of a Decoder.
+// The zero value uses the default for every option.
+type DecoderOptions struct {
+	// Reject bencode that is not in its canonical form, see WithStrict
+	Strict bool
+	// The maximum nesting depth of lists and dictionaries, see WithMaxDepth
+	MaxDepth int
+	// The maximum length of a single string, see WithMaxStringLength
+	MaxStringLength int
+	// The maximum length of all strings together, see WithMaxTotalBytes
+	MaxTotalBytes int
+	// Return strings as []byte in generic values, see WithByteStrings
+	ByteStrings bool
+	// Reject data after the top-level value, see WithDisallowTrailingData
+	DisallowTrailingData bool
+	// Share memory with the input of NewParserFromBytes, see WithZeroCopy
+	ZeroCopy bool
+}
+
+// An option to configure a Decoder
+type DecoderOption func(*DecoderOptions)
+
+// Sets all the options of a Decoder at once
+func WithOptions(options DecoderOptions) DecoderOption {
+	return func(o *DecoderOptions) {
+		*o = options
+	}
+}
+
+// Rejects bencode that is not in its canonical form: numbers with leading
+// zeros, negative zero or a plus sign and dictionaries with unsorted or
+// duplicate keys. Useful when the exact encoding matters, such as when
+// hashing peer-supplied data.
+func WithStrict() DecoderOption {
+	return func(o *DecoderOptions) {
+		o.Strict = true
+	}
+}
+
+// Sets the maximum nesting depth of lists and dictionaries.
+// Deeper values fail with ErrMaxDepth instead of growing the stack
+// without bounds. A non-positive depth uses DefaultMaxDepth.
+func WithMaxDepth(depth int) DecoderOption {
+	return func(o *DecoderOptions) {
+		o.MaxDepth = depth
+	}
+}
+
+// Sets the maximum length of a single string.
+// Longer strings fail with ErrLargeStringLen before any memory is
+// allocated for them. A non-positive length uses DefaultMaxStringLength.
+func WithMaxStringLength(length int) DecoderOption {
+	return func(o *DecoderOptions) {
+		o.MaxStringLength = length
+	}
+}
+
+// Sets the maximum length of all the strings (including dictionary keys)
+// read by the decoder together. Once exceeded reads fail with
+// ErrMaxTotalBytes. A non-positive value means no limit, which is the default.
+func WithMaxTotalBytes(total int) DecoderOption {
+	return func(o *DecoderOptions) {
+		o.MaxTotalBytes = total
+	}
+}
+
+// Returns strings as []byte instead of string in the values returned by
+// AsInterface, AsList, AsDict and Decode into an interface{}. Dictionary
+// keys are still returned as strings. Useful for binary data such as
+// piece hashes or compact peer lists.
+func WithByteStrings() DecoderOption {
+	return func(o *DecoderOptions) {
+		o.ByteStrings = true
+	}
+}
+
+// Rejects anything but the end of the input after the top-level value
+// with ErrTrailingData, so that concatenated or padded input doesn't decode
+// successfully. The check reads ahead by one byte, so with an io.Reader it
+// waits until the reader returns io.EOF.
+func WithDisallowTrailingData() DecoderOption {
+	return func(o *DecoderOptions) {
+		o.DisallowTrailingData = true
+	}
+}
+
+// Makes a decoder from NewParserFromBytes return strings and []byte values
+// that share memory with its input instead of copies, which avoids an
+// allocation per string. The input must not be modified as long as the
+// decoded values are in use. Other decoders ignore this option.
+func WithZeroCopy() DecoderOption {
+	return func(o *DecoderOptions) {
+		o.ZeroCopy = true
+	}
 }
 
 // A bencode decoder
-type decoder struct {
+type Decoder struct {
 	bencodeReader
+	options DecoderOptions
+	// The current nesting depth
+	depth int
+	// The length of all strings read so far
+	total int
+	// The lists and dictionaries opened by Token
+	tokens []tokenFrame
+}
+
+// Returns a decoder for a given reader with the given options applied
+func newDecoder(reader bencodeReader, opts []DecoderOption) *Decoder {
+	d := &Decoder{
+		bencodeReader: reader,
+	}
+	for _, opt := range opts {
+		opt(&d.options)
+	}
+	if d.options.MaxDepth <= 0 {
+		d.options.MaxDepth = DefaultMaxDepth
+	}
+	if d.options.MaxStringLength <= 0 {
+		d.options.MaxStringLength = DefaultMaxStringLength
+	}
+	return d
+}
+
+// Returns a bencode decoder reading from a given io.Reader
+func NewDecoder(reader io.Reader, opts ...DecoderOption) *Decoder {
+	return NewParserFromReader(reader, opts...)
+}
+
+// Returns the options of the decoder
+func (d *Decoder) Options() DecoderOptions {
+	return d.options
+}
+
+// Returns the number of input bytes consumed by the decoder so far.
+// After a value is decoded it is the offset of the first byte following
+// it, which lets callers slice off data that comes after the bencode.
+func (d *Decoder) InputOffset() int64 {
+	return d.offset()
+}
+
+// Returns the data that was read from the input but not consumed by the
+// decoder. It is empty for a decoder from an io.Reader that hasn't read
+// ahead, and the rest of the input for a decoder from a string.
+// The reader is only valid until the next call to the decoder.
+func (d *Decoder) Buffered() io.Reader {
+	return d.unread()
+}
+
+// Returns the input that follows the bytes consumed by the decoder: the
+// buffered data followed by the rest of the underlying io.Reader, if any.
+// The reader is only valid until the next call to the decoder.
+func (d *Decoder) Remaining() io.Reader {
+	return d.remaining()
+}
+
+// Enters a list or dictionary, enforcing the maximum depth.
+// Expects the type byte t to have just been read.
+// MUST be followed by a call to leave() if successful.
+func (d *Decoder) enter(t byte) error {
+	if d.depth >= d.options.MaxDepth {
+		expected := fmt.Sprintf("at most %d nested lists and dictionaries", d.options.MaxDepth)
+		return syntaxError(d.offset()-1, expected, t, ErrMaxDepth)
+	}
+	d.depth++
+	return nil
+}
+
+// Leaves a list or dictionary
+func (d *Decoder) leave() {
+	d.depth--
+}
+
+// Checks that a number is in its canonical form in strict mode
+func (d *Decoder) checkNumber(number string) error {
+	if d.options.Strict && len(number) > 0 {
+		if number[0] == '+' {
+			return ErrPlusSign
+		}
+		digits := number
+		if digits[0] == '-' {
+			digits = digits[1:]
+		}
+		if len(digits) > 1 && digits[0] == '0' {
+			return ErrLeadingZero
+		}
+		if number == "-0" {
+			return ErrNegativeZero
+		}
+	}
+	return nil
+}
+
+// Parses a number, enforcing the canonical form in strict mode
+func (d *Decoder) parseInt(number string) (int, error) {
+	if err := d.checkNumber(number); err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(number)
+}
+
+// Parses an unsigned number, accepting a sign like strconv.Atoi does.
+// Negative numbers other than zero are out of range.
+func parseUint64(number string) (uint64, error) {
+	if len(number) > 1 && number[0] == '-' {
+		if n, err := strconv.ParseUint(number[1:], 10, 64); err != nil || n != 0 {
+			if err == nil || errors.Is(err, strconv.ErrRange) {
+				err = strconv.ErrRange
+			} else {
+				err = strconv.ErrSyntax
+			}
+			return 0, &strconv.NumError{Func: "ParseUint", Num: number, Err: err}
+		}
+		return 0, nil
+	}
+	if len(number) > 1 && number[0] == '+' {
+		number = number[1:]
+	}
+	return strconv.ParseUint(number, 10, 64)
+}
+
+// The maximum length of a number read by AsBigInt, as parsing it takes
+// quadratic time. Longer numbers wouldn't fit in the buffer of a
+// readerParser either.
+const maxBigIntLength = bufferSize - 1
+
+// Parses a number of up to maxBigIntLength bytes
+func parseBigInt(number string) (*big.Int, error) {
+	if len(number) > maxBigIntLength {
+		return nil, &strconv.NumError{Func: "SetString", Num: number, Err: strconv.ErrRange}
+	}
+	// SetString also accepts a sign and leading zeros, like strconv.Atoi
+	n, ok := new(big.Int).SetString(number, 10)
+	if !ok {
+		return nil, &strconv.NumError{Func: "SetString", Num: number, Err: strconv.ErrSyntax}
+	}
+	return n, nil
+}
+
+// Reads a number until an end byte, returning it unparsed along with its offset
+func (d *Decoder) readNumber(separator byte) (string, int64, error) {
+	start := d.offset()
+	number, err := d.readNumberTo(separator)
+	if err != nil {
+		return "", start, d.eofError(expectedNumber(separator), err)
+	}
+	return number, start, nil
+}
+
+// Parses a number read from a given offset
+func (d *Decoder) parseNumber(number string, start int64, separator byte) (int, error) {
+	n, err := d.parseInt(number)
+	if err != nil {
+		return 0, numberError(number, start, separator, err)
+	}
+	return n, nil
+}
+
+// Reads a number until an end byte and parses it
+func (d *Decoder) readIntTo(separator byte) (int, error) {
+	number, start, err := d.readNumber(separator)
+	if err != nil {
+		return 0, err
+	}
+	return d.parseNumber(number, start, separator)
+}
+
+// Reads an integer until the 'e' and passes it to parse, enforcing the
+// canonical form in strict mode
+func (d *Decoder) readIntWith(parse func(number string) error) error {
+	number, start, err := d.readNumber('e')
+	if err != nil {
+		return err
+	}
+	if err := d.checkNumber(number); err != nil {
+		return numberError(number, start, 'e', err)
+	}
+	if err := parse(number); err != nil {
+		return numberError(number, start, 'e', err)
+	}
+	return nil
+}
+
+// Reads an integer of any size until the 'e', checking only its syntax.
+// Used when the value is skipped or kept as is.
+func (d *Decoder) readIntAnySize() (number string, err error) {
+	err = d.readIntWith(func(n string) error {
+		number = n
+		return checkIntSyntax(n)
+	})
+	return number, err
+}
+
+// Checks that a number is made of digits, accepting a sign like
+// strconv.Atoi does but not limiting its range
+func checkIntSyntax(number string) error {
+	digits := number
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits = digits[1:]
+	}
+	if digits == "" {
+		return &strconv.NumError{Func: "Atoi", Num: number, Err: strconv.ErrSyntax}
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return &strconv.NumError{Func: "Atoi", Num: number, Err: strconv.ErrSyntax}
+		}
+	}
+	return nil
+}
+
+// Reads an integer until the 'e' as an int64
+func (d *Decoder) readInt64() (n int64, err error) {
+	err = d.readIntWith(func(number string) (err error) {
+		n, err = strconv.ParseInt(number, 10, 64)
+		return err
+	})
+	return n, err
+}
+
+// Reads an integer until the 'e' as an uint64
+func (d *Decoder) readUint64() (n uint64, err error) {
+	err = d.readIntWith(func(number string) (err error) {
+		n, err = parseUint64(number)
+		return err
+	})
+	return n, err
+}
+
+// Reads an integer until the 'e' as a big.Int
+func (d *Decoder) readBigInt() (n *big.Int, err error) {
+	err = d.readIntWith(func(number string) (err error) {
+		n, err = parseBigInt(number)
+		return err
+	})
+	return n, err
+}
+
+// Reads a dictionary key, or reports the end of the dictionary.
+// In strict mode the key must sort after prev, the previous key (if any).
+func (d *Decoder) readKey(prev string, hasPrev bool) (key string, end bool, err error) {
+	// Check if end
+	if t, err := d.readByteIn("dictionary key or end"); err != nil {
+		return "", false, err
+	} else if t == 'e' {
+		return "", true, nil
+	} else if t == 'i' || t == 'l' || t == 'd' {
+		return "", false, syntaxError(d.offset()-1, "dictionary key", t, ErrInvalidType)
+	}
+	d.undoReadByte()
+	// Read key
+	start := d.offset()
+	key, err = d.asString()
+	if err != nil {
+		return "", false, err
+	}
+	return key, false, d.checkKey(prev, key, hasPrev, start)
+}
+
+// Checks that a dictionary key starting at a given offset sorts after
+// prev, the previous key (if any), in strict mode.
+func (d *Decoder) checkKey(prev, key string, hasPrev bool, start int64) error {
+	if d.options.Strict && hasPrev {
+		if key == prev {
+			return syntaxError(start, fmt.Sprintf("key after %q", prev), 0, ErrDuplicateKey)
+		} else if key < prev {
+			return syntaxError(start, fmt.Sprintf("key after %q", prev), 0, ErrUnsortedKeys)
+		}
+	}
+	return nil
+}
+
+// Marks the end of a value read by an exported method and returns err
+func (d *Decoder) done(err error) error {
+	if err == nil {
+		err = d.valueDone()
+	}
+	return err
+}
+
+// Checks that the input ends after a top-level value
+func (d *Decoder) checkEnd() error {
+	b, err := d.readByte()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	d.undoReadByte()
+	return syntaxError(d.offset(), "end of input", b, ErrTrailingData)
 }
 
 // Reads a single integer from the decoder.
-func (d *decoder) AsInt() (int, error) {
+func (d *Decoder) AsInt() (int, error) {
 	if b, err := d.readByte(); err != nil {
 		return 0, err
 	} else if b != 'i' {
-		return 0, ErrInvalidType
+		return 0, syntaxError(d.offset()-1, "integer", b, ErrInvalidType)
+	}
+	n, err := d.readIntTo('e')
+	return n, d.done(err)
+}
+
+// Reads a single integer from the decoder as an int64.
+// Unlike AsInt, it can read numbers larger than 2^31 on 32-bit platforms.
+func (d *Decoder) AsInt64() (int64, error) {
+	if b, err := d.readByte(); err != nil {
+		return 0, err
+	} else if b != 'i' {
+		return 0, syntaxError(d.offset()-1, "integer", b, ErrInvalidType)
+	}
+	n, err := d.readInt64()
+	return n, d.done(err)
+}
+
+// Reads a single non-negative integer from the decoder as an uint64.
+// Negative numbers fail with strconv.ErrRange.
+func (d *Decoder) AsUint64() (uint64, error) {
+	if b, err := d.readByte(); err != nil {
+		return 0, err
+	} else if b != 'i' {
+		return 0, syntaxError(d.offset()-1, "integer", b, ErrInvalidType)
+	}
+	n, err := d.readUint64()
+	return n, d.done(err)
+}
+
+// Reads a single integer of any size from the decoder.
+// Numbers longer than 1023 digits fail with strconv.ErrRange.
+func (d *Decoder) AsBigInt() (*big.Int, error) {
+	if b, err := d.readByte(); err != nil {
+		return nil, err
+	} else if b != 'i' {
+		return nil, syntaxError(d.offset()-1, "integer", b, ErrInvalidType)
+	}
+	n, err := d.readBigInt()
+	return n, d.done(err)
+}
+
+// Reads the length of a string, up to and including the ':'.
+// If reserve is true the length is taken from the maximum total bytes.
+func (d *Decoder) readLength(reserve bool) (int, error) {
+	number, start, err := d.readNumber(':')
+	if err != nil {
+		return 0, err
+	}
+	return d.parseLength(number, start, reserve)
+}
+
+// Parses the length of a string read from a given offset and checks it
+// against the maximum length.
+// If reserve is true the length is taken from the maximum total bytes.
+func (d *Decoder) parseLength(number string, start int64, reserve bool) (int, error) {
+	length, err := d.parseNumber(number, start, ':')
+	if err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return 0, syntaxError(start, "string length", '-', ErrInvalidStringLen)
+	}
+	if length > d.options.MaxStringLength {
+		expected := fmt.Sprintf("string length of at most %d", d.options.MaxStringLength)
+		return 0, syntaxError(start, expected, 0, ErrLargeStringLen)
+	}
+	if reserve && d.options.MaxTotalBytes > 0 {
+		if length > d.options.MaxTotalBytes-d.total {
+			expected := fmt.Sprintf("at most %d bytes of strings", d.options.MaxTotalBytes)
+			return 0, syntaxError(start, expected, 0, ErrMaxTotalBytes)
+		}
+		d.total += length
 	}
-	return d.readIntTo('e')
+	return length, nil
+}
+
+// Reads the content of a string of a given length
+func (d *Decoder) readStringData(length int) (string, error) {
+	s, err := d.readString(length)
+	if err != nil {
+		return "", d.eofError(fmt.Sprintf("string of length %d", length), err)
+	}
+	return s, nil
+}
+
+// Reads the content of a string of a given length as bytes
+func (d *Decoder) readBytesData(length int) ([]byte, error) {
+	b, err := d.readBytes(length)
+	if err != nil {
+		return nil, d.eofError(fmt.Sprintf("string of length %d", length), err)
+	}
+	return b, nil
 }
 
 // Reads a single string from the decoder.
-func (d *decoder) AsString() (string, error) {
-	length, err := d.readIntTo(':')
+func (d *Decoder) asString() (string, error) {
+	length, err := d.readLength(true)
 	if err != nil {
 		return "", err
 	}
-	if length < 0 {
-		return "", ErrInvalidStringLen
+	return d.readStringData(length)
+}
+
+// Reads a single string from the decoder as bytes.
+func (d *Decoder) asBytes() ([]byte, error) {
+	length, err := d.readLength(true)
+	if err != nil {
+		return nil, err
 	}
-	return d.readString(length)
+	return d.readBytesData(length)
+}
+
+// Checks that the next value is a string without consuming it
+func (d *Decoder) expectString() error {
+	if b, err := d.readByte(); err != nil {
+		return err
+	} else if b == 'i' || b == 'l' || b == 'd' || b == 'e' {
+		return syntaxError(d.offset()-1, "string", b, ErrInvalidType)
+	}
+	d.undoReadByte()
+	return nil
+}
+
+// Reads a single string from the decoder.
+func (d *Decoder) AsString() (string, error) {
+	if err := d.expectString(); err != nil {
+		return "", err
+	}
+	s, err := d.asString()
+	return s, d.done(err)
+}
+
+// Reads a single string from the decoder as bytes.
+// The returned slice is not shared with the decoder, except with
+// WithZeroCopy where it is a sub-slice of the input.
+func (d *Decoder) AsBytes() ([]byte, error) {
+	if err := d.expectString(); err != nil {
+		return nil, err
+	}
+	b, err := d.asBytes()
+	return b, d.done(err)
 }
 
 // Reads a single list from the decoder.
 // Assumes that the first 'l' has been read.
-func (d *decoder) asList() ([]interface{}, error) {
+func (d *Decoder) asList() ([]interface{}, error) {
+	if err := d.enter('l'); err != nil {
+		return nil, err
+	}
+	defer d.leave()
 	list := []interface{}{}
 	for {
 		// Read type
-		t, err := d.readByte()
+		t, err := d.readByteIn("list item or end")
 		if err != nil {
 			return list, err
 		}
@@ -76,34 +638,36 @@ func (d *decoder) asList() ([]interface{}, error) {
 }
 
 // Reads a single list from the decoder.
-func (d *decoder) AsList() ([]interface{}, error) {
+func (d *Decoder) AsList() ([]interface{}, error) {
 	if b, err := d.readByte(); err != nil {
 		return nil, err
 	} else if b != 'l' {
-		return nil, ErrInvalidType
+		return nil, syntaxError(d.offset()-1, "list", b, ErrInvalidType)
 	}
-	return d.asList()
+	list, err := d.asList()
+	return list, d.done(err)
 }
 
 // Reads a single dictionary from the decoder.
 // Assumes that the first 'd' has been read.
-func (d *decoder) asDict() (map[string]interface{}, error) {
+func (d *Decoder) asDict() (map[string]interface{}, error) {
+	if err := d.enter('d'); err != nil {
+		return nil, err
+	}
+	defer d.leave()
 	dict := map[string]interface{}{}
+	prev := ""
 	for {
-		// Check if end
-		if t, err := d.readByte(); err != nil {
-			return dict, err
-		} else if t == 'e' {
-			break
-		}
-		d.undoReadByte()
 		// Read key
-		key, err := d.AsString()
+		key, end, err := d.readKey(prev, len(dict) > 0)
 		if err != nil {
 			return dict, err
+		} else if end {
+			break
 		}
+		prev = key
 		// Read value's type
-		t, err := d.readByte()
+		t, err := d.readByteIn("dictionary value")
 		if err != nil {
 			return dict, err
 		}
@@ -119,18 +683,78 @@ func (d *decoder) asDict() (map[string]interface{}, error) {
 }
 
 // Reads a single dictionary from the decoder.
-func (d *decoder) AsDict() (map[string]interface{}, error) {
+func (d *Decoder) AsDict() (map[string]interface{}, error) {
 	if b, err := d.readByte(); err != nil {
 		return nil, err
 	} else if b != 'd' {
-		return nil, ErrInvalidType
+		return nil, syntaxError(d.offset()-1, "dictionary", b, ErrInvalidType)
 	}
-	return d.asDict()
+	dict, err := d.asDict()
+	return dict, d.done(err)
+}
+
+// Skips a value without allocating its strings.
+// Expects a type byte to be provided.
+func (d *Decoder) skipValue(t byte) error {
+	switch t {
+	case 'i':
+		_, err := d.readIntAnySize()
+		return err
+	case 'l':
+		if err := d.enter(t); err != nil {
+			return err
+		}
+		defer d.leave()
+		for {
+			t, err := d.readByteIn("list item or end")
+			if err != nil {
+				return err
+			}
+			if t == 'e' {
+				return nil
+			}
+			if err := d.skipValue(t); err != nil {
+				return err
+			}
+		}
+	case 'd':
+		if err := d.enter(t); err != nil {
+			return err
+		}
+		defer d.leave()
+		prev := ""
+		for i := 0; ; i++ {
+			// Keys are read to check their order in strict mode
+			key, end, err := d.readKey(prev, i > 0)
+			if err != nil {
+				return err
+			} else if end {
+				return nil
+			}
+			prev = key
+			t, err := d.readByteIn("dictionary value")
+			if err != nil {
+				return err
+			}
+			if err := d.skipValue(t); err != nil {
+				return err
+			}
+		}
+	}
+	d.undoReadByte()
+	length, err := d.readLength(false)
+	if err != nil {
+		return err
+	}
+	if err := d.discard(length); err != nil {
+		return d.eofError(fmt.Sprintf("string of length %d", length), err)
+	}
+	return nil
 }
 
 // Reads from the decoder and returns an interface.
 // Expects a type byte to be provided.
-func (d *decoder) asInterface(t byte) (interface{}, error) {
+func (d *Decoder) asInterface(t byte) (interface{}, error) {
 	if t == 'i' {
 		return d.readIntTo('e')
 	} else if t == 'l' {
@@ -139,14 +763,27 @@ func (d *decoder) asInterface(t byte) (interface{}, error) {
 		return d.asDict()
 	}
 	d.undoReadByte()
-	return d.AsString()
+	if d.options.ByteStrings {
+		return d.asBytes()
+	}
+	return d.asString()
 }
 
 // Reads from the decoder and returns an interface.
-func (d *decoder) AsInterface() (interface{}, error) {
+func (d *Decoder) AsInterface() (interface{}, error) {
 	t, err := d.readByte()
 	if err != nil {
 		return nil, err
 	}
-	return d.asInterface(t)
+	obj, err := d.asInterface(t)
+	return obj, d.done(err)
+}
+
+// Decodes a single value that must span the whole data, failing with
+// ErrTrailingData if anything follows it. See AsInterface for the types
+// of the returned value.
+func DecodeAll(data []byte, opts ...DecoderOption) (interface{}, error) {
+	d := NewParserFromBytes(data, opts...)
+	d.options.DisallowTrailingData = true
+	return d.AsInterface()
 }
@@ -0,0 +1,436 @@
+package bencode_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+func TestStrictDecoder(t *testing.T) {
+	// Canonical bencode is accepted
+	canonical := []string{}
+	for _, str := range stringsTestCases {
+		canonical = append(canonical, fmt.Sprintf("%d:%s", len(str), str))
+	}
+	for test := range intsTestCases {
+		canonical = append(canonical, test)
+	}
+	for test := range slicesTestCases {
+		canonical = append(canonical, test)
+	}
+	for test := range mapTestCases {
+		canonical = append(canonical, test)
+	}
+	for _, test := range canonical {
+		if _, err := bencode.NewParserFromString(test, bencode.WithStrict()).AsInterface(); err != nil {
+			t.Fatalf("Expected canonical %q to be accepted by strict stringParser, got %v", test, err)
+		}
+		if _, err := bencode.NewParserFromReader(strings.NewReader(test), bencode.WithStrict()).AsInterface(); err != nil {
+			t.Fatalf("Expected canonical %q to be accepted by strict readerParser, got %v", test, err)
+		}
+	}
+	// Non-canonical bencode is rejected only in strict mode
+	for test, expected := range strictInvalidTestCases {
+		if out, err := bencode.NewParserFromString(test).AsInterface(); err != nil {
+			t.Fatalf("Expected %q to be accepted by non-strict stringParser, got (%v, %v)", test, out, err)
+		}
+		if out, err := bencode.NewParserFromReader(strings.NewReader(test)).AsInterface(); err != nil {
+			t.Fatalf("Expected %q to be accepted by non-strict readerParser, got (%v, %v)", test, out, err)
+		}
+		if out, err := bencode.NewParserFromString(test, bencode.WithStrict()).AsInterface(); !errors.Is(err, expected) {
+			t.Fatalf("Expected %q to fail in strict stringParser with %v, got (%v, %v)", test, expected, out, err)
+		}
+		if out, err := bencode.NewParserFromReader(strings.NewReader(test), bencode.WithStrict()).AsInterface(); !errors.Is(err, expected) {
+			t.Fatalf("Expected %q to fail in strict readerParser with %v, got (%v, %v)", test, expected, out, err)
+		}
+		var v interface{}
+		if err := bencode.NewParserFromString(test, bencode.WithStrict()).Decode(&v); !errors.Is(err, expected) {
+			t.Fatalf("Expected %q to fail in strict Decode with %v, got (%v, %v)", test, expected, v, err)
+		}
+	}
+	// A negative length with a leading zero is invalid, but reported as such in strict mode
+	if out, err := bencode.NewParserFromString("-01:", bencode.WithStrict()).AsInterface(); !errors.Is(err, bencode.ErrLeadingZero) {
+		t.Fatalf("Expected ErrLeadingZero for %q, got (%v, %v)", "-01:", out, err)
+	}
+	// Strict mode also applies to struct decoding
+	var info testInfo
+	if err := bencode.NewParserFromString("d4:name1:x4:name1:ye", bencode.WithStrict()).Decode(&info); !errors.Is(err, bencode.ErrDuplicateKey) {
+		t.Fatalf("Expected ErrDuplicateKey when decoding into a struct, got (%v, %v)", info, err)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	// Nesting just within the limit is accepted
+	nested := strings.Repeat("l", 3) + strings.Repeat("e", 3)
+	if _, err := bencode.NewParserFromString(nested, bencode.WithMaxDepth(3)).AsInterface(); err != nil {
+		t.Fatalf("Expected %q to be accepted with a max depth of 3, got %v", nested, err)
+	}
+	var v [][][]int
+	if err := bencode.NewParserFromString(nested, bencode.WithMaxDepth(3)).Decode(&v); err != nil {
+		t.Fatalf("Expected %q to be decoded with a max depth of 3, got %v", nested, err)
+	}
+	// Nesting beyond the limit is rejected
+	for _, test := range []string{"llllee", "ld1:ald1:adeeee", "d1:ad1:ad1:ad1:adeeeee"} {
+		if out, err := bencode.NewParserFromString(test, bencode.WithMaxDepth(3)).AsInterface(); !errors.Is(err, bencode.ErrMaxDepth) {
+			t.Fatalf("Expected %q to fail with ErrMaxDepth, got (%v, %v)", test, out, err)
+		}
+		if out, err := bencode.NewParserFromReader(strings.NewReader(test), bencode.WithMaxDepth(3)).AsInterface(); !errors.Is(err, bencode.ErrMaxDepth) {
+			t.Fatalf("Expected %q to fail with ErrMaxDepth from reader, got (%v, %v)", test, out, err)
+		}
+		var v interface{}
+		if err := bencode.NewParserFromString(test, bencode.WithMaxDepth(3)).Decode(&v); !errors.Is(err, bencode.ErrMaxDepth) {
+			t.Fatalf("Expected %q to fail with ErrMaxDepth in Decode, got (%v, %v)", test, v, err)
+		}
+	}
+	// The default limit stops malicious payloads
+	malicious := strings.Repeat("l", 10*bencode.DefaultMaxDepth)
+	if out, err := bencode.NewParserFromReader(strings.NewReader(malicious)).AsList(); !errors.Is(err, bencode.ErrMaxDepth) {
+		t.Fatalf("Expected deeply nested list to fail with ErrMaxDepth, got (%v, %v)", out, err)
+	}
+	var obj interface{}
+	if err := bencode.NewParserFromReader(strings.NewReader(malicious)).Decode(&obj); !errors.Is(err, bencode.ErrMaxDepth) {
+		t.Fatalf("Expected deeply nested list to fail with ErrMaxDepth in Decode, got (%v, %v)", obj, err)
+	}
+}
+
+func TestNewDecoder(t *testing.T) {
+	// The exported type can be named
+	var decoder *bencode.Decoder = bencode.NewDecoder(strings.NewReader(debianTorrentEncoded))
+	if out, err := decoder.AsInterface(); err != nil || !reflect.DeepEqual(out, debianTorrent) {
+		t.Fatalf("Expected %v from NewDecoder, got (%v, %v)", debianTorrent, out, err)
+	}
+	// Options are applied in order
+	options := bencode.NewDecoder(strings.NewReader(""),
+		bencode.WithMaxDepth(3),
+		bencode.WithOptions(bencode.DecoderOptions{Strict: true}),
+	).Options()
+	if expected := (bencode.DecoderOptions{
+		Strict:          true,
+		MaxDepth:        bencode.DefaultMaxDepth,
+		MaxStringLength: bencode.DefaultMaxStringLength,
+	}); options != expected {
+		t.Fatalf("Expected options %+v, got %+v", expected, options)
+	}
+	options = bencode.NewDecoder(strings.NewReader(""),
+		bencode.WithOptions(bencode.DecoderOptions{Strict: true}),
+		bencode.WithMaxDepth(3),
+	).Options()
+	if expected := (bencode.DecoderOptions{
+		Strict:          true,
+		MaxDepth:        3,
+		MaxStringLength: bencode.DefaultMaxStringLength,
+	}); options != expected {
+		t.Fatalf("Expected options %+v, got %+v", expected, options)
+	}
+	// Options are per instance
+	if _, err := bencode.NewDecoder(strings.NewReader("i03e"), bencode.WithStrict()).AsInt(); !errors.Is(err, bencode.ErrLeadingZero) {
+		t.Fatalf("Expected ErrLeadingZero from strict decoder, got %v", err)
+	}
+	if _, err := bencode.NewDecoder(strings.NewReader("i03e")).AsInt(); err != nil {
+		t.Fatalf("Expected non-strict decoder to accept \"i03e\", got %v", err)
+	}
+}
+
+func TestMaxStringLength(t *testing.T) {
+	// Strings up to the limit are accepted
+	for _, test := range []string{"5:hello", "l5:hello5:worlde", "d5:hello5:worlde"} {
+		if _, err := bencode.NewParserFromString(test, bencode.WithMaxStringLength(5)).AsInterface(); err != nil {
+			t.Fatalf("Expected %q to be accepted with a max string length of 5, got %v", test, err)
+		}
+		if _, err := bencode.NewParserFromReader(strings.NewReader(test), bencode.WithMaxStringLength(5)).AsInterface(); err != nil {
+			t.Fatalf("Expected %q to be accepted by reader with a max string length of 5, got %v", test, err)
+		}
+	}
+	// Longer strings are rejected the same way by both backends
+	for _, test := range []string{"6:hello!", "l6:hello!e", "d6:hello!0:e", "99999999999:", fmt.Sprintf("%d:", math.MaxInt)} {
+		if out, err := bencode.NewParserFromString(test, bencode.WithMaxStringLength(5)).AsInterface(); !errors.Is(err, bencode.ErrLargeStringLen) {
+			t.Fatalf("Expected %q to fail with ErrLargeStringLen, got (%v, %v)", test, out, err)
+		}
+		if out, err := bencode.NewParserFromReader(strings.NewReader(test), bencode.WithMaxStringLength(5)).AsInterface(); !errors.Is(err, bencode.ErrLargeStringLen) {
+			t.Fatalf("Expected %q to fail with ErrLargeStringLen from reader, got (%v, %v)", test, out, err)
+		}
+	}
+	// Huge lengths fail without panicking even with a larger limit
+	huge := fmt.Sprintf("%d:abc", math.MaxInt)
+	if out, err := bencode.NewParserFromString(huge, bencode.WithMaxStringLength(math.MaxInt)).AsString(); err == nil {
+		t.Fatalf("Expected %q to fail, got %q", huge, out)
+	}
+}
+
+func TestMaxTotalBytes(t *testing.T) {
+	// Total within the budget
+	test := "d5:hello5:world2:hil2:hi2:hiee"
+	if _, err := bencode.NewParserFromString(test, bencode.WithMaxTotalBytes(16)).AsInterface(); err != nil {
+		t.Fatalf("Expected %q to be accepted with max total bytes of 16, got %v", test, err)
+	}
+	// Total beyond the budget
+	if out, err := bencode.NewParserFromString(test, bencode.WithMaxTotalBytes(15)).AsInterface(); !errors.Is(err, bencode.ErrMaxTotalBytes) {
+		t.Fatalf("Expected %q to fail with ErrMaxTotalBytes, got (%v, %v)", test, out, err)
+	}
+	if out, err := bencode.NewParserFromReader(strings.NewReader(test), bencode.WithMaxTotalBytes(15)).AsInterface(); !errors.Is(err, bencode.ErrMaxTotalBytes) {
+		t.Fatalf("Expected %q to fail with ErrMaxTotalBytes from reader, got (%v, %v)", test, out, err)
+	}
+	// The budget is shared by all values read from the decoder
+	decoder := bencode.NewParserFromString("5:hello5:world", bencode.WithMaxTotalBytes(8))
+	if _, err := decoder.AsString(); err != nil {
+		t.Fatalf("Expected first string to be accepted, got %v", err)
+	}
+	if out, err := decoder.AsString(); !errors.Is(err, bencode.ErrMaxTotalBytes) {
+		t.Fatalf("Expected second string to fail with ErrMaxTotalBytes, got (%q, %v)", out, err)
+	}
+}
+
+func TestAsBytes(t *testing.T) {
+	large := strings.Repeat("\x00\xff", 1500)
+	tests := append([]string{large}, stringsTestCases...)
+	for _, test := range tests {
+		encoded := fmt.Sprintf("%d:%s", len(test), test)
+		decoders := []*bencode.Decoder{
+			bencode.NewParserFromString(encoded),
+			bencode.NewParserFromReader(strings.NewReader(encoded)),
+			bencode.NewParserFromReader(newChaosReader(encoded)),
+		}
+		for _, decoder := range decoders {
+			if b, err := decoder.AsBytes(); err != nil || string(b) != test {
+				t.Fatalf("Expected AsBytes to return %q, got (%q, %v)", test, b, err)
+			}
+		}
+	}
+	// Other types are rejected
+	for _, test := range []string{"i1e", "le", "de", "e"} {
+		if b, err := bencode.NewParserFromString(test).AsBytes(); !errors.Is(err, bencode.ErrInvalidType) {
+			t.Fatalf("Expected AsBytes of %q to fail with ErrInvalidType, got (%q, %v)", test, b, err)
+		}
+	}
+	if b, err := bencode.NewParserFromString("5:abc").AsBytes(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected AsBytes of a short string to fail with io.ErrUnexpectedEOF, got (%q, %v)", b, err)
+	}
+}
+
+func TestByteStrings(t *testing.T) {
+	test := "d2:id3:\x00\xff\x105:nodesl0:1:ai4ee4:sizei7ee"
+	expected := map[string]interface{}{
+		"id":    []byte{0x00, 0xff, 0x10},
+		"nodes": []interface{}{[]byte{}, []byte("a"), 4},
+		"size":  7,
+	}
+	decoders := []*bencode.Decoder{
+		bencode.NewParserFromString(test, bencode.WithByteStrings()),
+		bencode.NewParserFromReader(strings.NewReader(test), bencode.WithByteStrings()),
+	}
+	for _, decoder := range decoders {
+		if out, err := decoder.AsInterface(); err != nil || !reflect.DeepEqual(out, expected) {
+			t.Fatalf("Expected %v, got (%v, %v)", expected, out, err)
+		}
+	}
+	var v interface{}
+	if err := bencode.NewParserFromString(test, bencode.WithByteStrings()).Decode(&v); err != nil || !reflect.DeepEqual(v, expected) {
+		t.Fatalf("Expected Decode to return %v, got (%v, %v)", expected, v, err)
+	}
+	// Strings are the default
+	if out, err := bencode.NewParserFromString("3:abc").AsInterface(); err != nil || out != "abc" {
+		t.Fatalf("Expected a string by default, got (%v, %v)", out, err)
+	}
+}
+
+func TestLargeIntegers(t *testing.T) {
+	for test, expected := range map[string]string{
+		"i0e":                                 "0",
+		"i-1e":                                "-1",
+		"i2147483648e":                        "2147483648",
+		"i9223372036854775807e":               "9223372036854775807",
+		"i-9223372036854775808e":              "-9223372036854775808",
+		"i18446744073709551615e":              "18446744073709551615",
+		"i-18446744073709551616e":             "-18446744073709551616",
+		"i" + strings.Repeat("9", 1023) + "e": strings.Repeat("9", 1023),
+	} {
+		expectedBig := bigInt(expected)
+		// Pad the input so numbers cross the buffer of a readerParser
+		padded := strings.Repeat("0:", 500) + test
+		decoders := []*bencode.Decoder{
+			bencode.NewParserFromString(padded),
+			bencode.NewParserFromReader(strings.NewReader(padded)),
+		}
+		for _, decoder := range decoders {
+			for i := 0; i < 500; i++ {
+				decoder.Skip()
+			}
+			if n, err := decoder.AsBigInt(); err != nil || n.Cmp(expectedBig) != 0 {
+				t.Fatalf("Expected AsBigInt of %q to return %v, got (%v, %v)", test, expected, n, err)
+			}
+		}
+		// Skipped and raw values are not limited to any range
+		if err := bencode.NewParserFromString(test, bencode.WithDisallowTrailingData()).Skip(); err != nil {
+			t.Fatalf("Expected Skip of %q to succeed, got %v", test, err)
+		}
+		if raw, err := bencode.NewParserFromString(test).AsRaw(); err != nil || string(raw) != test {
+			t.Fatalf("Expected AsRaw of %q to return it, got (%q, %v)", test, raw, err)
+		}
+		var skipped struct {
+			B string `bencode:"b"`
+		}
+		if err := bencode.Unmarshal([]byte("d1:a"+test+"1:b3:xyze"), &skipped); err != nil || skipped.B != "xyz" {
+			t.Fatalf("Expected Unmarshal to skip %q, got (%v, %v)", test, skipped, err)
+		}
+		n, err := bencode.NewParserFromString(test).AsInt64()
+		if expectedBig.IsInt64() {
+			if err != nil || n != expectedBig.Int64() {
+				t.Fatalf("Expected AsInt64 of %q to return %v, got (%v, %v)", test, expected, n, err)
+			}
+		} else if !errors.Is(err, strconv.ErrRange) {
+			t.Fatalf("Expected AsInt64 of %q to fail with strconv.ErrRange, got (%v, %v)", test, n, err)
+		}
+		u, err := bencode.NewParserFromString(test).AsUint64()
+		if expectedBig.IsUint64() {
+			if err != nil || u != expectedBig.Uint64() {
+				t.Fatalf("Expected AsUint64 of %q to return %v, got (%v, %v)", test, expected, u, err)
+			}
+		} else if !errors.Is(err, strconv.ErrRange) {
+			t.Fatalf("Expected AsUint64 of %q to fail with strconv.ErrRange, got (%v, %v)", test, u, err)
+		}
+	}
+	// Invalid numbers
+	for _, test := range []string{"ie", "i-e", "i1-e", "i--1e", "i+-1e", "i1.5e", "5:hello"} {
+		if n, err := bencode.NewParserFromString(test).AsInt64(); err == nil {
+			t.Fatalf("Expected AsInt64 of %q to fail, got %v", test, n)
+		}
+		if n, err := bencode.NewParserFromString(test).AsUint64(); err == nil {
+			t.Fatalf("Expected AsUint64 of %q to fail, got %v", test, n)
+		}
+		if n, err := bencode.NewParserFromString(test).AsBigInt(); err == nil {
+			t.Fatalf("Expected AsBigInt of %q to fail, got %v", test, n)
+		}
+		if raw, err := bencode.NewParserFromString(test).AsRaw(); err == nil && test[0] == 'i' {
+			t.Fatalf("Expected AsRaw of %q to fail, got %q", test, raw)
+		}
+	}
+	// Huge numbers are rejected before parsing
+	huge := "i" + strings.Repeat("9", 1024) + "e"
+	if n, err := bencode.NewParserFromString(huge).AsBigInt(); !errors.Is(err, strconv.ErrRange) {
+		t.Fatalf("Expected AsBigInt of a huge number to fail with strconv.ErrRange, got (%v, %v)", n, err)
+	}
+	// Strict mode applies to all sizes
+	for test, expected := range map[string]error{"i-0e": bencode.ErrNegativeZero, "i+1e": bencode.ErrPlusSign, "i01e": bencode.ErrLeadingZero} {
+		if _, err := bencode.NewParserFromString(test, bencode.WithStrict()).AsUint64(); !errors.Is(err, expected) {
+			t.Fatalf("Expected strict AsUint64 of %q to fail with %v, got %v", test, expected, err)
+		}
+		if _, err := bencode.NewParserFromString(test, bencode.WithStrict()).AsBigInt(); !errors.Is(err, expected) {
+			t.Fatalf("Expected strict AsBigInt of %q to fail with %v, got %v", test, expected, err)
+		}
+		if err := bencode.NewParserFromString(test, bencode.WithStrict()).Skip(); !errors.Is(err, expected) {
+			t.Fatalf("Expected strict Skip of %q to fail with %v, got %v", test, expected, err)
+		}
+	}
+}
+
+func TestInputOffset(t *testing.T) {
+	data := "d1:ai1e1:bl1:cee" + "payload"
+	decoders := map[string]func() *bencode.Decoder{
+		"string": func() *bencode.Decoder { return bencode.NewParserFromString(data) },
+		"reader": func() *bencode.Decoder { return bencode.NewParserFromReader(strings.NewReader(data)) },
+	}
+	for name, newDecoder := range decoders {
+		d := newDecoder()
+		if offset := d.InputOffset(); offset != 0 {
+			t.Fatalf("Expected offset 0 before decoding (%s), got %d", name, offset)
+		}
+		if _, err := d.Token(); err != nil {
+			t.Fatalf("Token returned error (%s): %v", name, err)
+		}
+		if offset := d.InputOffset(); offset != 1 {
+			t.Fatalf("Expected offset 1 after the first token (%s), got %d", name, offset)
+		}
+		d = newDecoder()
+		if _, err := d.AsDict(); err != nil {
+			t.Fatalf("AsDict returned error (%s): %v", name, err)
+		}
+		if offset := d.InputOffset(); offset != 16 || data[offset:] != "payload" {
+			t.Fatalf("Expected offset 16 (%s), got %d", name, offset)
+		}
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	payload := strings.Repeat("0123456789", 300)
+	data := "d1:ai1ee" + payload
+	// The string backend has the whole rest of the input buffered
+	d := bencode.NewParserFromString(data)
+	if _, err := d.AsDict(); err != nil {
+		t.Fatalf("AsDict returned error: %v", err)
+	}
+	for name, r := range map[string]io.Reader{"Buffered": d.Buffered(), "Remaining": d.Remaining()} {
+		if rest, err := io.ReadAll(r); err != nil || string(rest) != payload {
+			t.Fatalf("Expected %s to return the payload, got %d bytes (%v)", name, len(rest), err)
+		}
+	}
+	// The reader backend has only read ahead up to its buffer
+	d = bencode.NewParserFromReader(strings.NewReader(data))
+	if _, err := d.AsDict(); err != nil {
+		t.Fatalf("AsDict returned error: %v", err)
+	}
+	buffered, err := io.ReadAll(d.Buffered())
+	if err != nil || len(buffered) == 0 || len(buffered) >= len(payload) || !strings.HasPrefix(payload, string(buffered)) {
+		t.Fatalf("Expected Buffered to return the start of the payload, got %q (%v)", buffered, err)
+	}
+	if rest, err := io.ReadAll(d.Remaining()); err != nil || string(rest) != payload {
+		t.Fatalf("Expected Remaining to return the payload, got %d bytes (%v)", len(rest), err)
+	}
+	// Nothing is buffered before the first read
+	d = bencode.NewParserFromReader(strings.NewReader(data))
+	if buffered, err := io.ReadAll(d.Buffered()); err != nil || len(buffered) != 0 {
+		t.Fatalf("Expected no buffered data, got %q (%v)", buffered, err)
+	}
+}
+
+func TestDisallowTrailingData(t *testing.T) {
+	valid := []string{"i1e", "4:spam", "le", "d1:ali1ei2eee"}
+	for _, test := range valid {
+		if _, err := bencode.DecodeAll([]byte(test)); err != nil {
+			t.Fatalf("DecodeAll of %q returned error: %v", test, err)
+		}
+		d := bencode.NewParserFromReader(strings.NewReader(test), bencode.WithDisallowTrailingData())
+		if _, err := d.AsInterface(); err != nil {
+			t.Fatalf("AsInterface of %q returned error: %v", test, err)
+		}
+	}
+	trailing := map[string]int64{"i1ei2e": 3, "4:spam\n": 6, "lee": 2, "d1:ali1ei2eeed": 13}
+	for test, offset := range trailing {
+		if _, err := bencode.DecodeAll([]byte(test)); !errors.Is(err, bencode.ErrTrailingData) {
+			t.Fatalf("Expected DecodeAll of %q to fail with ErrTrailingData, got %v", test, err)
+		}
+		var syntaxErr *bencode.SyntaxError
+		d := bencode.NewParserFromReader(strings.NewReader(test), bencode.WithDisallowTrailingData())
+		if _, err := d.AsInterface(); !errors.As(err, &syntaxErr) || syntaxErr.Offset != offset || !errors.Is(err, bencode.ErrTrailingData) {
+			t.Fatalf("Expected ErrTrailingData at offset %d for %q, got %v", offset, test, err)
+		}
+		// Without the option the trailing data is left for the next read
+		if _, err := bencode.NewParserFromString(test).AsInterface(); err != nil {
+			t.Fatalf("AsInterface of %q returned error: %v", test, err)
+		}
+	}
+	// Tokens and Decode check the end of the input once the top-level value is complete
+	d := bencode.NewParserFromString("li1eei2e", bencode.WithDisallowTrailingData())
+	for _, expected := range []bencode.TokenType{bencode.ListStart, bencode.Int} {
+		if token, err := d.Token(); err != nil || token.Type != expected {
+			t.Fatalf("Expected %v, got (%v, %v)", expected, token, err)
+		}
+	}
+	if _, err := d.Token(); !errors.Is(err, bencode.ErrTrailingData) {
+		t.Fatalf("Expected ErrTrailingData, got %v", err)
+	}
+	var v []int
+	if err := bencode.NewParserFromString("li1eex", bencode.WithDisallowTrailingData()).Decode(&v); !errors.Is(err, bencode.ErrTrailingData) {
+		t.Fatalf("Expected ErrTrailingData, got %v", err)
+	}
+	// Truncated input is still an unexpected end
+	if _, err := bencode.DecodeAll([]byte("li1e")); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
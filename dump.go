@@ -0,0 +1,207 @@
+package bencode
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// The options of Dump
+type DumpOptions struct {
+	// The indentation of each level, see WithDumpIndent
+	Indent string
+	// The number of bytes shown of longer strings, see WithDumpMaxLength
+	MaxLength int
+}
+
+// An option to configure Dump
+type DumpOption func(*DumpOptions)
+
+// Sets the indentation of each level of lists and dictionaries.
+// The default is two spaces.
+func WithDumpIndent(indent string) DumpOption {
+	return func(o *DumpOptions) {
+		o.Indent = indent
+	}
+}
+
+// Shortens strings longer than a given number of bytes, such as the
+// pieces of a torrent, to their first bytes followed by their length.
+// A non-positive length shows strings in full, which is the default.
+func WithDumpMaxLength(length int) DumpOption {
+	return func(o *DumpOptions) {
+		o.MaxLength = length
+	}
+}
+
+// Writes a human-readable tree of the bencode value read from a reader.
+//
+// Dictionary keys keep the order of the input. Integers are written as
+// numbers, printable strings are quoted and binary strings are written in
+// hex between angle brackets, such as <00ff>. For example "d3:agei35e4:name5:Alicee" is:
+//
+//	{
+//	  "age": 35
+//	  "name": "Alice"
+//	}
+func Dump(w io.Writer, r io.Reader, opts ...DumpOption) error {
+	return NewParserFromReader(r).Dump(w, opts...)
+}
+
+// Reads a single value from the decoder and writes it as a human-readable
+// tree. See Dump for the format.
+//
+// What was decoded before an error is still written, which helps finding
+// where invalid input goes wrong.
+func (d *Decoder) Dump(w io.Writer, opts ...DumpOption) error {
+	o := DumpOptions{Indent: "  "}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	bw := bufio.NewWriter(w)
+	err := d.dumpValue(bw, &o, 0)
+	if err == nil {
+		err = bw.WriteByte('\n')
+	}
+	if flushErr := bw.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// Writes the next value at a given nesting level
+func (d *Decoder) dumpValue(w *bufio.Writer, o *DumpOptions, level int) error {
+	next, err := d.Next()
+	if err != nil {
+		return err
+	}
+	switch next {
+	case Int:
+		// Read integers as big.Int so that no valid value fails to print
+		n, err := d.AsBigInt()
+		if err != nil {
+			return err
+		}
+		_, err = w.WriteString(n.String())
+		return err
+	case String:
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		return dumpString(w, token.String, o.MaxLength)
+	case ListStart, DictStart:
+		return d.dumpContainer(w, o, level, next == DictStart)
+	}
+	// An unexpected end of a list or dictionary
+	_, err = d.Token()
+	return err
+}
+
+// Writes a list or dictionary at a given nesting level
+func (d *Decoder) dumpContainer(w *bufio.Writer, o *DumpOptions, level int, dict bool) error {
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	open, end := "[", "]"
+	if dict {
+		open, end = "{", "}"
+	}
+	if _, err := w.WriteString(open); err != nil {
+		return err
+	}
+	empty := true
+	for {
+		next, err := d.Next()
+		if err != nil {
+			return d.eofError("value or end", err)
+		}
+		if next == End {
+			break
+		}
+		empty = false
+		if err := dumpLine(w, o, level+1); err != nil {
+			return err
+		}
+		if dict {
+			key, err := d.Token()
+			if err != nil {
+				return err
+			}
+			// Keys are never shortened
+			if err := dumpString(w, key.String, 0); err != nil {
+				return err
+			}
+			if _, err := w.WriteString(": "); err != nil {
+				return err
+			}
+		}
+		if err := d.dumpValue(w, o, level+1); err != nil {
+			return err
+		}
+	}
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	if !empty {
+		if err := dumpLine(w, o, level); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString(end)
+	return err
+}
+
+// Starts a new line at a given nesting level
+func dumpLine(w *bufio.Writer, o *DumpOptions, level int) error {
+	if err := w.WriteByte('\n'); err != nil {
+		return err
+	}
+	for i := 0; i < level; i++ {
+		if _, err := w.WriteString(o.Indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes a string quoted if printable or in hex otherwise, shortened to
+// a maximum length if positive
+func dumpString(w *bufio.Writer, s string, maxLength int) error {
+	printable := isPrintable(s)
+	shown := s
+	if maxLength > 0 && len(s) > maxLength {
+		cut := maxLength
+		// Don't cut a printable string in the middle of a character
+		for printable && cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		shown = s[:cut]
+	}
+	var err error
+	if printable {
+		_, err = w.WriteString(strconv.Quote(shown))
+	} else {
+		_, err = w.WriteString("<" + hex.EncodeToString([]byte(shown)) + ">")
+	}
+	if err == nil && len(shown) < len(s) {
+		_, err = w.WriteString("... (" + strconv.Itoa(len(s)) + " bytes)")
+	}
+	return err
+}
+
+// Returns true if a string is valid UTF-8 text without control characters
+// other than whitespace
+func isPrintable(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !strconv.IsPrint(r) && r != '\t' && r != '\n' && r != '\r' {
+			return false
+		}
+	}
+	return true
+}
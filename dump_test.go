@@ -0,0 +1,59 @@
+package bencode_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+var (
+	dumpTestCases = map[string]string{
+		"i-42e":                            "-42",
+		"i123456789012345678901234567890e": "123456789012345678901234567890",
+		"2:42":                             `"42"`,
+		"0:":                               `""`,
+		"3:\x00\x01\xff":                   "<0001ff>",
+		"le":                               "[]",
+		"de":                               "{}",
+		"li1e1:ae":                         "[\n  1\n  \"a\"\n]",
+		"d1:bi1e1:ali2ee2:\xff\x00dee":     "{\n  \"b\": 1\n  \"a\": [\n    2\n  ]\n  <ff00>: {}\n}",
+	}
+)
+
+func TestDump(t *testing.T) {
+	for test, expected := range dumpTestCases {
+		var out strings.Builder
+		if err := bencode.Dump(&out, strings.NewReader(test)); err != nil {
+			t.Fatalf("Dump of %q returned error: %v", test, err)
+		}
+		if out.String() != expected+"\n" {
+			t.Fatalf("Expected %q for %q, got %q", expected+"\n", test, out.String())
+		}
+	}
+	// Custom indentation and shortened strings
+	test := "d4:name7:héllo!6:pieces40:" + strings.Repeat("\x00\x01", 20) + "e"
+	expected := "{\n\t\"name\": \"h\"... (7 bytes)\n\t\"pieces\": <0001>... (40 bytes)\n}\n"
+	var out strings.Builder
+	if err := bencode.NewParserFromString(test).Dump(&out, bencode.WithDumpIndent("\t"), bencode.WithDumpMaxLength(2)); err != nil {
+		t.Fatalf("Dump returned error: %v", err)
+	}
+	if out.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, out.String())
+	}
+	// The output before an error is kept
+	out.Reset()
+	if err := bencode.Dump(&out, strings.NewReader("li1ei2")); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if out.String() != "[\n  1\n  " {
+		t.Fatalf("Expected the partial output, got %q", out.String())
+	}
+	for _, invalid := range []string{"", "e", "l", "d", "di1ei1ee", "x"} {
+		if err := bencode.Dump(io.Discard, strings.NewReader(invalid)); err == nil {
+			t.Fatalf("Expected Dump of %q to fail", invalid)
+		}
+	}
+}
@@ -1,149 +1,243 @@
 package bencode
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"io"
+	"math/big"
+	"sort"
 	"strconv"
 )
 
-// A bencode encoder
-type encoder struct {
+// The output of an encoder
+type encoderWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// A bencode encoder.
+// The zero value is an empty encoder ready to use.
+type Encoder struct {
 	buffer bytes.Buffer
+	// If set, the output is streamed to this writer instead of the buffer
+	writer *bufio.Writer
+	// Scratch space to format numbers
+	scratch [24]byte
+}
+
+// Returns an encoder that streams its output to a given io.Writer.
+//
+// Output is buffered and flushed at the end of every Encode call, with
+// any write error returned to the caller. Since nothing is kept in memory,
+// String, Bytes and WriteTo return an empty output for this encoder.
+func NewEncoder(writer io.Writer) *Encoder {
+	return &Encoder{
+		writer: bufio.NewWriter(writer),
+	}
 }
 
 // Writes data to a writer
-func (e encoder) WriteTo(writer io.Writer) (n int64, err error) {
+func (e Encoder) WriteTo(writer io.Writer) (n int64, err error) {
 	return e.buffer.WriteTo(writer)
 }
 
 // Return a string representation of the encoded bencode
-func (e encoder) String() string {
+func (e Encoder) String() string {
 	return e.buffer.String()
 }
 
 // Return a bytes representation of the encoded bencode
-func (e encoder) Bytes() []byte {
+func (e Encoder) Bytes() []byte {
 	return e.buffer.Bytes()
 }
 
+// Returns the output of the encoder.
+//
+// Both outputs keep the first write error and return it on every
+// following write, so it's enough to check the last write of a sequence.
+func (e *Encoder) output() encoderWriter {
+	if e.writer != nil {
+		return e.writer
+	}
+	return &e.buffer
+}
+
+// Writes any buffered output to the underlying io.Writer
+func (e *Encoder) flush() error {
+	if e.writer != nil {
+		return e.writer.Flush()
+	}
+	return nil
+}
+
 // Writes an integer to the encoder output
-func (e *encoder) writeInt(i int64) {
-	e.buffer.WriteRune('i')
-	e.buffer.WriteString(strconv.FormatInt(i, 10))
-	e.buffer.WriteRune('e')
+func (e *Encoder) writeInt(i int64) error {
+	w := e.output()
+	w.WriteByte('i')
+	w.Write(strconv.AppendInt(e.scratch[:0], i, 10))
+	return w.WriteByte('e')
 }
 
 // Writes an unsigned integer to the encoder output
-func (e *encoder) writeUint(u uint64) {
-	e.buffer.WriteRune('i')
-	e.buffer.WriteString(strconv.FormatUint(u, 10))
-	e.buffer.WriteRune('e')
+func (e *Encoder) writeUint(u uint64) error {
+	w := e.output()
+	w.WriteByte('i')
+	w.Write(strconv.AppendUint(e.scratch[:0], u, 10))
+	return w.WriteByte('e')
+}
+
+// Writes an integer of any size to the encoder output
+func (e *Encoder) writeBigInt(n *big.Int) error {
+	if n == nil {
+		return fmt.Errorf("%w: nil *big.Int", ErrUnsupportedType)
+	}
+	w := e.output()
+	w.WriteByte('i')
+	w.Write(n.Append(e.scratch[:0], 10))
+	return w.WriteByte('e')
 }
 
 // Writes a string to the encoder output
-func (e *encoder) writeString(s string) {
-	e.buffer.Grow(len(s) + 10)
-	e.buffer.WriteString(strconv.FormatInt(int64(len(s)), 10))
-	e.buffer.WriteRune(':')
-	e.buffer.WriteString(s)
-}
-
-// Writes a map[string]interface to the encoder output
-func (e *encoder) writeMap(m map[string]interface{}) error {
-	e.buffer.WriteByte('d')
-	for k, v := range m {
-		e.writeString(k)
-		if err := e.writeAuto(v); err != nil {
+func (e *Encoder) writeString(s string) error {
+	if e.writer == nil {
+		e.buffer.Grow(len(s) + 10)
+	}
+	w := e.output()
+	w.Write(strconv.AppendInt(e.scratch[:0], int64(len(s)), 10))
+	w.WriteByte(':')
+	_, err := w.WriteString(s)
+	return err
+}
+
+// Writes a []byte as a string to the encoder output
+func (e *Encoder) writeBytes(b []byte) error {
+	if e.writer == nil {
+		e.buffer.Grow(len(b) + 10)
+	}
+	w := e.output()
+	w.Write(strconv.AppendInt(e.scratch[:0], int64(len(b)), 10))
+	w.WriteByte(':')
+	_, err := w.Write(b)
+	return err
+}
+
+// Writes a map[string]interface to the encoder output.
+// Keys are sorted as raw byte strings, as required by the bencode spec.
+func (e *Encoder) writeMap(m map[string]interface{}) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	w := e.output()
+	w.WriteByte('d')
+	for _, k := range keys {
+		if err := e.writeString(k); err != nil {
+			return err
+		}
+		if err := e.writeAuto(m[k]); err != nil {
 			return err
 		}
 	}
-	e.buffer.WriteByte('e')
-	return nil
+	return w.WriteByte('e')
 }
 
 // Writes a slice []interface to the encoder output
-func (e *encoder) writeSlice(l []interface{}) error {
-	e.buffer.WriteByte('l')
+func (e *Encoder) writeSlice(l []interface{}) error {
+	w := e.output()
+	w.WriteByte('l')
 	for _, v := range l {
 		if err := e.writeAuto(v); err != nil {
 			return err
 		}
 	}
-	e.buffer.WriteByte('e')
-	return nil
+	return w.WriteByte('e')
 }
 
 // Automatically identify the type of an interface and write it to the encoder output
-func (e *encoder) writeAuto(v interface{}) error {
+func (e *Encoder) writeAuto(v interface{}) error {
 	switch v := v.(type) {
 	case string:
-		e.writeString(v)
+		return e.writeString(v)
+	case []byte:
+		return e.writeBytes(v)
 	case []interface{}:
 		return e.writeSlice(v)
 	case map[string]interface{}:
 		return e.writeMap(v)
 	case int:
-		e.writeInt(int64(v))
+		return e.writeInt(int64(v))
 	case uint:
-		e.writeUint(uint64(v))
+		return e.writeUint(uint64(v))
 	case int64:
-		e.writeInt(v)
+		return e.writeInt(v)
 	case uint64:
-		e.writeUint(v)
+		return e.writeUint(v)
 	case int32:
-		e.writeInt(int64(v))
+		return e.writeInt(int64(v))
 	case uint32:
-		e.writeUint(uint64(v))
+		return e.writeUint(uint64(v))
 	case int16:
-		e.writeInt(int64(v))
+		return e.writeInt(int64(v))
 	case uint16:
-		e.writeUint(uint64(v))
+		return e.writeUint(uint64(v))
 	case int8:
-		e.writeInt(int64(v))
+		return e.writeInt(int64(v))
 	case uint8:
-		e.writeUint(uint64(v))
+		return e.writeUint(uint64(v))
+	case *big.Int:
+		return e.writeBigInt(v)
+	case Marshaler:
+		return e.writeMarshaler(v)
 	default:
 		return fmt.Errorf("can't format interface of type %T: %v", v, v)
 	}
-	return nil
 }
 
 // Returns a bencode encoder from a given int64
-func NewEncoderFromUint(u uint64) *encoder {
-	e := new(encoder)
+func NewEncoderFromUint(u uint64) *Encoder {
+	e := new(Encoder)
 	e.writeUint(u)
 	return e
 }
 
 // Returns a bencode encoder from a given int64
-func NewEncoderFromInt(i int64) *encoder {
-	e := new(encoder)
+func NewEncoderFromInt(i int64) *Encoder {
+	e := new(Encoder)
 	e.writeInt(i)
 	return e
 }
 
 // Returns a bencode encoder from a given string
-func NewEncoderFromString(s string) *encoder {
-	e := new(encoder)
+func NewEncoderFromString(s string) *Encoder {
+	e := new(Encoder)
 	e.writeString(s)
 	return e
 }
 
+// Returns a bencode encoder from a given []byte
+func NewEncoderFromBytes(b []byte) *Encoder {
+	e := new(Encoder)
+	e.writeBytes(b)
+	return e
+}
+
 // Returns a bencode encoder from a given slice
-func NewEncoderFromSlice(l []interface{}) (*encoder, error) {
-	e := new(encoder)
+func NewEncoderFromSlice(l []interface{}) (*Encoder, error) {
+	e := new(Encoder)
 	return e, e.writeSlice(l)
 }
 
 // Returns a bencode encoder from a given map[string]interface{}
-func NewEncoderFromMap(m map[string]interface{}) (*encoder, error) {
-	e := new(encoder)
+func NewEncoderFromMap(m map[string]interface{}) (*Encoder, error) {
+	e := new(Encoder)
 	return e, e.writeMap(m)
 }
 
 // Returns a bencode encoder from a given object
-func NewEncoderFromInterface(v interface{}) (*encoder, error) {
-	e := new(encoder)
+func NewEncoderFromInterface(v interface{}) (*Encoder, error) {
+	e := new(Encoder)
 	return e, e.writeAuto(v)
 }
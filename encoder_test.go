@@ -2,8 +2,11 @@ package bencode_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"math/big"
 	"runtime"
 	"strings"
 	"testing"
@@ -65,6 +68,22 @@ func TestStringEncoding(t *testing.T) {
 	}
 }
 
+func TestBytesEncoding(t *testing.T) {
+	for _, str := range stringsTestCases {
+		expected := fmt.Sprintf("%d:%s", len(str), str)
+		GenericEncoderTester(t, []byte(str), expected)
+		// Check bytes-specific encoding
+		if actualStr := bencode.NewEncoderFromBytes([]byte(str)).String(); actualStr != expected {
+			t.Fatalf("Expected %q (%x) from bytes-specific encoder doesn't match actual %q (%x)", expected, expected, actualStr, actualStr)
+		}
+	}
+	// Binary values nested in generic values
+	GenericEncoderTester(t, map[string]interface{}{
+		"id":    []byte{0x00, 0xff, 0x10},
+		"nodes": []interface{}{[]byte{}, []byte("a")},
+	}, "d2:id3:\x00\xff\x105:nodesl0:1:aee")
+}
+
 func TestIntEncoding(t *testing.T) {
 	for i, expected := range int64TestCases {
 		t.Logf("Testing %d with expected value %q", i, expected)
@@ -119,6 +138,16 @@ func TestUintEncoding(t *testing.T) {
 	}
 }
 
+func TestBigIntEncoding(t *testing.T) {
+	for _, number := range []string{"0", "-1", "18446744073709551616", "-123456789012345678901234567890"} {
+		GenericEncoderTester(t, bigInt(number), "i"+number+"e")
+	}
+	GenericEncoderTester(t, []interface{}{big.NewInt(1), 2}, "li1ei2ee")
+	if encoder, err := bencode.NewEncoderFromInterface((*big.Int)(nil)); err == nil {
+		t.Fatalf("Encoded a nil *big.Int without error as %v", encoder)
+	}
+}
+
 func TestSliceEncoding(t *testing.T) {
 	for expected, list := range slicesTestCases {
 		// Run Test
@@ -151,6 +180,89 @@ func TestMapEncoding(t *testing.T) {
 	}
 }
 
+func TestCanonicalMapEncoding(t *testing.T) {
+	for expected, m := range mapTestCases {
+		// Map iteration order is random, so repeat to catch unsorted output
+		for i := 0; i < 100; i++ {
+			encoder, err := bencode.NewEncoderFromInterface(m)
+			if err != nil {
+				t.Fatalf("Got unexpected error from encoder: %v", err)
+			}
+			if actualStr := encoder.String(); actualStr != expected {
+				t.Fatalf("Run %d: expected %q doesn't match actual %q", i, expected, actualStr)
+			}
+		}
+	}
+}
+
+func TestEncoderEncode(t *testing.T) {
+	var encoder bencode.Encoder
+	if err := encoder.Encode(debianTorrentStruct); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if err := encoder.Encode(complexMap); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if expected := debianTorrentSorted + complexMapCanonical; encoder.String() != expected {
+		t.Fatalf("Expected %q, but got %q", expected, encoder.String())
+	}
+	if err := encoder.Encode(true); !errors.Is(err, bencode.ErrUnsupportedType) {
+		t.Fatalf("Expected ErrUnsupportedType, but got %v", err)
+	}
+}
+
+// A writer that fails after a given number of bytes
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(p []byte) (n int, err error) {
+	if len(p) > w.remaining {
+		n = w.remaining
+		w.remaining = 0
+		return n, io.ErrShortWrite
+	}
+	w.remaining -= len(p)
+	return len(p), nil
+}
+
+func TestStreamingEncoder(t *testing.T) {
+	// Values are streamed to the writer
+	buf := bytes.Buffer{}
+	encoder := bencode.NewEncoder(&buf)
+	if err := encoder.Encode(debianTorrentStruct); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if buf.String() != debianTorrentSorted {
+		t.Fatalf("Expected %q, but got %q", debianTorrentSorted, buf.String())
+	}
+	if err := encoder.Encode(complexMap); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if expected := debianTorrentSorted + complexMapCanonical; buf.String() != expected {
+		t.Fatalf("Expected %q, but got %q", expected, buf.String())
+	}
+	if encoder.String() != "" {
+		t.Fatalf("Expected streaming encoder not to keep output, but got %q", encoder.String())
+	}
+	// Large values are streamed as well
+	large := map[string]interface{}{"pieces": strings.Repeat(extremelyLongString, 128)}
+	buf.Reset()
+	if err := bencode.NewEncoder(&buf).Encode(large); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if expected, _ := bencode.Marshal(large); !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf("Streaming encoding of a large value doesn't match Marshal")
+	}
+	// Write errors are returned
+	for _, limit := range []int{0, 10, 100, 5000, 100000} {
+		err := bencode.NewEncoder(&failingWriter{remaining: limit}).Encode(large)
+		if err != io.ErrShortWrite {
+			t.Fatalf("Expected io.ErrShortWrite with a limit of %d, got %v", limit, err)
+		}
+	}
+}
+
 func BenchmarkEncoder(b *testing.B) {
 	for benchName, testInterface := range encoderBenchmarks {
 		b.Run(benchName, func(b *testing.B) {
@@ -0,0 +1,101 @@
+package bencode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// An error in the bencode input along with its position.
+//
+// It wraps the underlying error, so errors.Is(err, ErrInvalidType) and
+// similar checks keep working on errors returned by a Decoder.
+type SyntaxError struct {
+	// The byte offset in the input where the error was found
+	Offset int64
+	// A description of what the decoder expected at Offset
+	Expected string
+	// The byte found at Offset, or 0 if the error isn't about a single
+	// byte (such as the end of the input or a limit being exceeded)
+	Actual byte
+	// The underlying error
+	Err error
+}
+
+// Returns a description of the error and its position
+func (e *SyntaxError) Error() string {
+	msg := fmt.Sprintf("%v at offset %d: expected %s", e.Err, e.Offset, e.Expected)
+	if e.Actual != 0 {
+		msg += fmt.Sprintf(", found %q", e.Actual)
+	}
+	return msg
+}
+
+// Returns the underlying error
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Returns a SyntaxError for an error found at a given offset
+func syntaxError(offset int64, expected string, actual byte, err error) error {
+	return &SyntaxError{
+		Offset:   offset,
+		Expected: expected,
+		Actual:   actual,
+		Err:      err,
+	}
+}
+
+// Returns a SyntaxError for the input ending at the current offset.
+// Other errors, such as those of the underlying io.Reader, are returned as is.
+func (d *Decoder) eofError(expected string, err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return syntaxError(d.offset(), expected, 0, io.ErrUnexpectedEOF)
+	}
+	return err
+}
+
+// Reads the next byte of a value, where the end of the input is unexpected
+func (d *Decoder) readByteIn(expected string) (byte, error) {
+	b, err := d.readByte()
+	if err != nil {
+		return 0, d.eofError(expected, err)
+	}
+	return b, nil
+}
+
+// Returns a description of a number ending with a given separator
+func expectedNumber(separator byte) string {
+	if separator == ':' {
+		return "string length"
+	}
+	return "integer"
+}
+
+// Returns the index of the first byte of a number that can't be parsed,
+// or the length of the number if it's incomplete (such as "" or "-")
+func invalidNumberIndex(number string) int {
+	for i := 0; i < len(number); i++ {
+		c := number[i]
+		if '0' <= c && c <= '9' || i == 0 && (c == '-' || c == '+') {
+			continue
+		}
+		return i
+	}
+	return len(number)
+}
+
+// Returns a SyntaxError for a number starting at a given offset that
+// failed to parse with err
+func numberError(number string, start int64, separator byte, err error) error {
+	i := 0
+	if errors.Is(err, strconv.ErrSyntax) {
+		i = invalidNumberIndex(number)
+	}
+	actual := separator
+	if i < len(number) {
+		actual = number[i]
+	}
+	return syntaxError(start+int64(i), expectedNumber(separator), actual, err)
+}
@@ -0,0 +1,109 @@
+package bencode_test
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+// An invalid input and where it should fail
+type syntaxErrorTestCase struct {
+	offset int64
+	actual byte
+	err    error
+}
+
+var (
+	syntaxErrorTestCases = map[string]syntaxErrorTestCase{
+		"i1x2e":          {2, 'x', strconv.ErrSyntax},
+		"ie":             {1, 'e', strconv.ErrSyntax},
+		"li1e":           {4, 0, io.ErrUnexpectedEOF},
+		"d1:ai1e":        {7, 0, io.ErrUnexpectedEOF},
+		"d1:a":           {4, 0, io.ErrUnexpectedEOF},
+		"5:abc":          {5, 0, io.ErrUnexpectedEOF},
+		"di1ei2ee":       {1, 'i', bencode.ErrInvalidType},
+		"-1:a":           {0, '-', bencode.ErrInvalidStringLen},
+		"l1:a1:x:e":      {7, ':', strconv.ErrSyntax},
+		"i03e":           {1, '0', bencode.ErrLeadingZero},
+		"d1:bi1e1:ai2ee": {7, 0, bencode.ErrUnsortedKeys},
+		"ld1:a0:1:a0:ee": {7, 0, bencode.ErrDuplicateKey},
+		"llllleeeee":     {4, 'l', bencode.ErrMaxDepth},
+		"l9999:xe":       {1, 0, bencode.ErrLargeStringLen},
+		// Offsets past the buffer of a readerParser
+		"l2000:" + strings.Repeat("x", 2000) + "i1xee": {2008, 'x', strconv.ErrSyntax},
+		strings.Repeat("0:", 1000) + "d1:a":            {2004, 0, io.ErrUnexpectedEOF},
+	}
+)
+
+// Checks that err is a SyntaxError matching a test case
+func checkSyntaxError(t *testing.T, test string, expected syntaxErrorTestCase, err error) {
+	var syntaxErr *bencode.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Expected %q to fail with a SyntaxError, got %v", test, err)
+	}
+	if !errors.Is(err, expected.err) {
+		t.Fatalf("Expected %q to fail with %v, got %v", test, expected.err, err)
+	}
+	if syntaxErr.Offset != expected.offset || syntaxErr.Actual != expected.actual {
+		t.Fatalf("Expected %q to fail at offset %d with %q, got %d with %q (%v)",
+			test, expected.offset, expected.actual, syntaxErr.Offset, syntaxErr.Actual, err)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	opts := []bencode.DecoderOption{
+		bencode.WithStrict(),
+		bencode.WithMaxDepth(4),
+		bencode.WithMaxStringLength(5000),
+	}
+	for test, expected := range syntaxErrorTestCases {
+		// Read values until the error
+		decoders := []*bencode.Decoder{
+			bencode.NewParserFromString(test, opts...),
+			bencode.NewParserFromReader(strings.NewReader(test), opts...),
+			bencode.NewParserFromBytes([]byte(test), opts...),
+		}
+		for _, decoder := range decoders {
+			var err error
+			for err == nil {
+				_, err = decoder.AsInterface()
+			}
+			checkSyntaxError(t, test, expected, err)
+		}
+		var raw bencode.RawMessage
+		decoder := bencode.NewParserFromString(test, opts...)
+		var err error
+		for err == nil {
+			err = decoder.Decode(&raw)
+		}
+		checkSyntaxError(t, test, expected, err)
+	}
+	// Type errors point to the value that can't be stored
+	typeErrors := map[string]syntaxErrorTestCase{
+		"li1e1:ae":   {4, '1', bencode.ErrInvalidType},
+		"d1:ai1ee":   {0, 'd', bencode.ErrInvalidType},
+		"li1ei256ee": {5, 0, bencode.ErrInvalidType},
+	}
+	for test, expected := range typeErrors {
+		var v []uint8
+		checkSyntaxError(t, test, expected, bencode.Unmarshal([]byte(test), &v))
+	}
+	if _, err := bencode.NewParserFromString("5:hello").AsInt(); err == nil {
+		t.Fatalf("Expected AsInt to fail on a string")
+	} else {
+		checkSyntaxError(t, "5:hello", syntaxErrorTestCase{0, '5', bencode.ErrInvalidType}, err)
+	}
+	// The end of the input between values is not a syntax error
+	if _, err := bencode.NewParserFromString("").AsInterface(); err != io.EOF {
+		t.Fatalf("Expected io.EOF on empty input, got %v", err)
+	}
+	// The error message includes the position
+	err := &bencode.SyntaxError{Offset: 3, Expected: "integer", Actual: 'x', Err: bencode.ErrInvalidType}
+	if msg := err.Error(); msg != "invalid bencode output type at offset 3: expected integer, found 'x'" {
+		t.Fatalf("Unexpected error message %q", msg)
+	}
+}
@@ -0,0 +1,68 @@
+// Package extension encodes and decodes the messages of the BitTorrent
+// extension protocol (BEP 10) and of the metadata extension (BEP 9).
+//
+// The payloads passed to the parse functions are the bytes following the
+// extended message ID, and the encode methods return the same. Payloads
+// come from untrusted peers, so they are decoded with a small maximum depth.
+package extension
+
+import (
+	"net/netip"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+const (
+	// The ID of extended messages in the peer wire protocol
+	ExtendedMessageID = 20
+	// The extended message ID of the handshake
+	HandshakeID = 0
+	// The maximum nesting depth of a payload
+	maxDepth = 8
+)
+
+// The extension handshake
+type Handshake struct {
+	// The extended message ID of each supported extension by name, such as
+	// "ut_metadata"; an ID of 0 disables a previously enabled extension
+	M map[string]int `bencode:"m"`
+	// The client name and version
+	V string `bencode:"v,omitempty"`
+	// The TCP port the client listens on
+	P int `bencode:"p,omitempty"`
+	// The number of outstanding requests the client supports
+	Reqq int `bencode:"reqq,omitempty"`
+	// The size of the info dictionary, for ut_metadata
+	MetadataSize int64 `bencode:"metadata_size,omitempty"`
+	// The compact IP address of the receiver as seen by the sender
+	YourIP []byte `bencode:"yourip,omitempty"`
+	// The compact IPv4 address of the sender
+	IPv4 []byte `bencode:"ipv4,omitempty"`
+	// The compact IPv6 address of the sender
+	IPv6 []byte `bencode:"ipv6,omitempty"`
+}
+
+// Returns the IP address of the receiver as seen by the sender, if valid
+func (h *Handshake) YourAddr() (netip.Addr, bool) {
+	return netip.AddrFromSlice(h.YourIP)
+}
+
+// Returns the extended message ID of an extension, or 0 if not supported
+func (h *Handshake) ID(name string) int {
+	return h.M[name]
+}
+
+// Decodes an extension handshake from its payload
+func ParseHandshake(payload []byte) (*Handshake, error) {
+	h := new(Handshake)
+	d := bencode.NewParserFromBytes(payload, bencode.WithMaxDepth(maxDepth))
+	if err := d.Decode(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Returns the payload of the extension handshake
+func (h *Handshake) Encode() ([]byte, error) {
+	return bencode.Marshal(h)
+}
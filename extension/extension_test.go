@@ -0,0 +1,50 @@
+package extension_test
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode/extension"
+)
+
+func TestHandshake(t *testing.T) {
+	payload := "d1:md11:ut_metadatai3e6:ut_pexi1ee13:metadata_sizei31235e1:pi6881e4:reqqi500e1:v12:uTorrent 3.56:yourip4:\x0a\x00\x00\x01e"
+	h, err := extension.ParseHandshake([]byte(payload))
+	if err != nil {
+		t.Fatalf("ParseHandshake returned error: %v", err)
+	}
+	expected := &extension.Handshake{
+		M:            map[string]int{"ut_metadata": 3, "ut_pex": 1},
+		V:            "uTorrent 3.5",
+		P:            6881,
+		Reqq:         500,
+		MetadataSize: 31235,
+		YourIP:       []byte{10, 0, 0, 1},
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, h)
+	}
+	if h.ID(extension.MetadataExtension) != 3 || h.ID("lt_donthave") != 0 {
+		t.Fatalf("Unexpected extension IDs %v", h.M)
+	}
+	if addr, ok := h.YourAddr(); !ok || addr != netip.MustParseAddr("10.0.0.1") {
+		t.Fatalf("Expected 10.0.0.1, got %v", addr)
+	}
+	encoded, err := h.Encode()
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if string(encoded) != payload {
+		t.Fatalf("Expected %q, got %q", payload, encoded)
+	}
+	// "m" is always encoded
+	if encoded, err = (&extension.Handshake{}).Encode(); err != nil || string(encoded) != "d1:mdee" {
+		t.Fatalf("Expected %q, got %q (%v)", "d1:mdee", encoded, err)
+	}
+	for _, invalid := range []string{"", "le", "d1:mi1ee", "d1:md1:a1:bee", "d1:xlllllllllleeeeeeeeeee"} {
+		if h, err := extension.ParseHandshake([]byte(invalid)); err == nil {
+			t.Fatalf("Expected ParseHandshake of %q to fail, got %+v", invalid, h)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package extension
+
+import (
+	"errors"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+var (
+	// Error for a ut_metadata message with an unknown type, a negative piece,
+	// or data that is missing, unexpected or larger than a piece
+	ErrInvalidMetadata = errors.New("extension: invalid ut_metadata message")
+)
+
+const (
+	// The name of the metadata extension in the handshake
+	MetadataExtension = "ut_metadata"
+	// The size of a metadata piece; only the last piece may be smaller
+	MetadataPieceSize = 16 * 1024
+)
+
+// The types of ut_metadata messages
+const (
+	MetadataRequest = 0
+	MetadataData    = 1
+	MetadataReject  = 2
+)
+
+// A ut_metadata message
+type MetadataMessage struct {
+	// The type of the message: MetadataRequest, MetadataData or MetadataReject
+	Type int `bencode:"msg_type"`
+	// The index of the metadata piece
+	Piece int `bencode:"piece"`
+	// The size of the info dictionary, only set in data messages
+	TotalSize int64 `bencode:"total_size,omitempty"`
+	// The metadata piece, which follows the dictionary in data messages
+	Data []byte `bencode:"-"`
+}
+
+// Decodes a ut_metadata message from its payload.
+// The data of a data message is a slice of the payload.
+func ParseMetadata(payload []byte) (*MetadataMessage, error) {
+	m := new(MetadataMessage)
+	d := bencode.NewParserFromBytes(payload, bencode.WithMaxDepth(maxDepth))
+	if err := d.Decode(m); err != nil {
+		return nil, err
+	}
+	if data := payload[d.InputOffset():]; len(data) > 0 {
+		m.Data = data
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Returns the payload of the ut_metadata message
+func (m *MetadataMessage) Encode() ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	payload, err := bencode.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return append(payload, m.Data...), nil
+}
+
+// Checks the type, the piece and the data of the message
+func (m *MetadataMessage) validate() error {
+	if m.Piece < 0 {
+		return ErrInvalidMetadata
+	}
+	switch m.Type {
+	case MetadataRequest, MetadataReject:
+		if len(m.Data) == 0 {
+			return nil
+		}
+	case MetadataData:
+		if len(m.Data) > 0 && len(m.Data) <= MetadataPieceSize && m.TotalSize > 0 {
+			return nil
+		}
+	}
+	return ErrInvalidMetadata
+}
@@ -0,0 +1,55 @@
+package extension_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode/extension"
+)
+
+func TestMetadata(t *testing.T) {
+	testCases := map[string]*extension.MetadataMessage{
+		"d8:msg_typei0e5:piecei0ee": {Type: extension.MetadataRequest},
+		"d8:msg_typei1e5:piecei1e10:total_sizei16387eexyz": {
+			Type:      extension.MetadataData,
+			Piece:     1,
+			TotalSize: 16387,
+			Data:      []byte("xyz"),
+		},
+		"d8:msg_typei2e5:piecei0ee": {Type: extension.MetadataReject},
+	}
+	for payload, expected := range testCases {
+		m, err := extension.ParseMetadata([]byte(payload))
+		if err != nil {
+			t.Fatalf("ParseMetadata of %q returned error: %v", payload, err)
+		}
+		if !reflect.DeepEqual(m, expected) {
+			t.Fatalf("Expected %+v, got %+v", expected, m)
+		}
+		encoded, err := m.Encode()
+		if err != nil {
+			t.Fatalf("Encode of %+v returned error: %v", m, err)
+		}
+		if string(encoded) != payload {
+			t.Fatalf("Expected %q, got %q", payload, encoded)
+		}
+	}
+	invalid := []string{
+		"",
+		"d8:msg_typei0e5:piecei0eexyz",
+		"d8:msg_typei1e5:piecei0e10:total_sizei1ee",
+		"d8:msg_typei1e5:piecei0ee" + "xyz",
+		"d8:msg_typei1e5:piecei0e10:total_sizei1ee" + strings.Repeat("x", extension.MetadataPieceSize+1),
+		"d8:msg_typei3e5:piecei0ee",
+		"d8:msg_typei0e5:piecei-1ee",
+	}
+	for _, payload := range invalid {
+		if m, err := extension.ParseMetadata([]byte(payload)); err == nil {
+			t.Fatalf("Expected ParseMetadata of %q to fail, got %+v", payload, m)
+		}
+	}
+	if _, err := (&extension.MetadataMessage{Type: extension.MetadataData}).Encode(); err != extension.ErrInvalidMetadata {
+		t.Fatalf("Expected ErrInvalidMetadata, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package bencode
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A struct field that can be encoded or decoded
+type field struct {
+	// The dictionary key for this field
+	name string
+	// The index sequence for reflect.Value.FieldByIndex
+	index []int
+	// If true, empty values are not encoded
+	omitEmpty bool
+}
+
+// The encodable fields of a struct type
+type structFields struct {
+	// Fields sorted by dictionary key
+	list []field
+	// Fields indexed by dictionary key
+	byName map[string]*field
+}
+
+// Cache of reflect.Type to *structFields
+var fieldCache sync.Map
+
+// Returns the fields of a struct type, using the cache if possible
+func cachedFields(t reflect.Type) *structFields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(*structFields)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.(*structFields)
+}
+
+// Parses a `bencode:"name,omitempty"` tag
+func parseTag(tag string) (name string, omitEmpty bool) {
+	name, opts, _ := strings.Cut(tag, ",")
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty
+}
+
+// Returns the fields of a struct type.
+// Fields of embedded structs without a tag are promoted unless
+// shadowed by a field with the same name closer to the surface.
+func typeFields(t reflect.Type) *structFields {
+	fields := []field{}
+	seen := map[string]bool{}
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		embedded := []reflect.StructField{}
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag, hasTag := sf.Tag.Lookup("bencode")
+			if tag == "-" {
+				continue
+			}
+			if sf.Anonymous && !hasTag && sf.Type.Kind() == reflect.Struct {
+				// Promote fields after the ones at this level
+				embedded = append(embedded, sf)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			name, omitEmpty := parseTag(tag)
+			if name == "" {
+				name = sf.Name
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			fields = append(fields, field{
+				name:      name,
+				index:     append(append([]int{}, index...), i),
+				omitEmpty: omitEmpty,
+			})
+		}
+		for _, sf := range embedded {
+			walk(sf.Type, append(append([]int{}, index...), sf.Index...))
+		}
+	}
+	walk(t, nil)
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].name < fields[j].name
+	})
+	byName := make(map[string]*field, len(fields))
+	for i := range fields {
+		byName[fields[i].name] = &fields[i]
+	}
+	return &structFields{
+		list:   fields,
+		byName: byName,
+	}
+}
@@ -0,0 +1,279 @@
+package bencode
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// The key of the JSON object that holds a string that is not UTF-8
+	jsonBytesKey = "$bytes"
+	// The prefix of a JSON object key that holds a key that is not UTF-8
+	jsonBytesKeyPrefix = "$bytes:"
+)
+
+// Converts the bencode value read from a reader to JSON.
+//
+// Dictionary keys keep the order of the input, integers of any size are
+// written as JSON numbers, and strings that are not valid UTF-8 are written
+// as {"$bytes": "<base64>"}. Dictionary keys that are not valid UTF-8 are
+// written as "$bytes:<base64>", and keys starting with "$" are escaped with
+// an additional "$". FromJSON reverses it, which returns the original bytes
+// of canonical input; non-canonical integers such as "i03e" or "i-0e" are
+// written in their canonical form, use WithStrict to reject them instead.
+func ToJSON(r io.Reader, w io.Writer, opts ...DecoderOption) error {
+	d := NewParserFromReader(r, opts...)
+	bw := bufio.NewWriter(w)
+	err := d.jsonValue(bw)
+	if err == nil {
+		err = bw.WriteByte('\n')
+	}
+	if flushErr := bw.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// Writes the next value as JSON
+func (d *Decoder) jsonValue(w *bufio.Writer) error {
+	next, err := d.Next()
+	if err != nil {
+		return err
+	}
+	switch next {
+	case Int:
+		n, err := d.AsBigInt()
+		if err != nil {
+			return err
+		}
+		_, err = w.WriteString(n.String())
+		return err
+	case String:
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if utf8.ValidString(token.String) {
+			return writeJSONString(w, token.String)
+		}
+		w.WriteString(`{"` + jsonBytesKey + `":"`)
+		w.WriteString(base64.StdEncoding.EncodeToString([]byte(token.String)))
+		_, err = w.WriteString(`"}`)
+		return err
+	case ListStart, DictStart:
+		return d.jsonContainer(w, next == DictStart)
+	}
+	// An unexpected end of a list or dictionary
+	_, err = d.Token()
+	return err
+}
+
+// Writes a list as a JSON array or a dictionary as a JSON object
+func (d *Decoder) jsonContainer(w *bufio.Writer, dict bool) error {
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	open, end := byte('['), byte(']')
+	if dict {
+		open, end = '{', '}'
+	}
+	if err := w.WriteByte(open); err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		next, err := d.Next()
+		if err != nil {
+			return d.eofError("value or end", err)
+		}
+		if next == End {
+			break
+		}
+		if !first {
+			w.WriteByte(',')
+		}
+		if dict {
+			key, err := d.Token()
+			if err != nil {
+				return err
+			}
+			if err := writeJSONString(w, jsonKey(key.String)); err != nil {
+				return err
+			}
+			w.WriteByte(':')
+		}
+		if err := d.jsonValue(w); err != nil {
+			return err
+		}
+	}
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	return w.WriteByte(end)
+}
+
+// Returns the JSON object key of a dictionary key
+func jsonKey(key string) string {
+	if !utf8.ValidString(key) {
+		return jsonBytesKeyPrefix + base64.StdEncoding.EncodeToString([]byte(key))
+	}
+	if strings.HasPrefix(key, "$") {
+		return "$" + key
+	}
+	return key
+}
+
+// Returns the dictionary key of a JSON object key
+func bencodeKey(key string) (string, error) {
+	if strings.HasPrefix(key, "$$") {
+		return key[1:], nil
+	}
+	if encoded, ok := strings.CutPrefix(key, jsonBytesKeyPrefix); ok {
+		b, err := base64.StdEncoding.DecodeString(encoded)
+		return string(b), err
+	}
+	return key, nil
+}
+
+// Writes a UTF-8 string as a JSON string
+func writeJSONString(w *bufio.Writer, s string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Converts a JSON value read from a reader to bencode.
+//
+// It reverses ToJSON: object keys are written in the order of the input,
+// without sorting them, and {"$bytes": "<base64>"} objects are written as
+// strings. JSON numbers must be integers; they and other values that can't
+// be represented in bencode, such as booleans and null, fail with
+// ErrUnsupportedType. Anything but whitespace after the JSON value fails.
+func FromJSON(r io.Reader, w io.Writer) error {
+	jd := json.NewDecoder(r)
+	jd.UseNumber()
+	e := NewEncoder(w)
+	token, err := jd.Token()
+	if err != nil {
+		return err
+	}
+	if err := e.writeJSON(jd, token); err != nil {
+		return err
+	}
+	if _, err := jd.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("invalid JSON: data after the top-level value")
+		}
+		return err
+	}
+	return e.flush()
+}
+
+// Writes a JSON value, starting with a given token, as bencode
+func (e *Encoder) writeJSON(jd *json.Decoder, token json.Token) error {
+	switch token := token.(type) {
+	case string:
+		return e.writeString(token)
+	case json.Number:
+		n, ok := new(big.Int).SetString(token.String(), 10)
+		if !ok {
+			return fmt.Errorf("%w: JSON number %s is not an integer", ErrUnsupportedType, token)
+		}
+		return e.writeBigInt(n)
+	case json.Delim:
+		if token == '[' {
+			return e.writeJSONArray(jd)
+		}
+		return e.writeJSONObject(jd)
+	}
+	return fmt.Errorf("%w: JSON %v", ErrUnsupportedType, token)
+}
+
+// Writes the rest of a JSON array as a list
+func (e *Encoder) writeJSONArray(jd *json.Decoder) error {
+	e.output().WriteByte('l')
+	for jd.More() {
+		token, err := jd.Token()
+		if err != nil {
+			return err
+		}
+		if err := e.writeJSON(jd, token); err != nil {
+			return err
+		}
+	}
+	// The closing bracket
+	if _, err := jd.Token(); err != nil {
+		return err
+	}
+	return e.output().WriteByte('e')
+}
+
+// Writes the rest of a JSON object as a dictionary, or as a string if it
+// is a {"$bytes": "<base64>"} object
+func (e *Encoder) writeJSONObject(jd *json.Decoder) error {
+	first := true
+	for jd.More() {
+		token, err := jd.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+		if first {
+			if key == jsonBytesKey {
+				return e.writeJSONBytes(jd)
+			}
+			e.output().WriteByte('d')
+			first = false
+		}
+		if key, err = bencodeKey(key); err != nil {
+			return err
+		}
+		e.writeString(key)
+		if token, err = jd.Token(); err != nil {
+			return err
+		}
+		if err := e.writeJSON(jd, token); err != nil {
+			return err
+		}
+	}
+	if first {
+		// An empty object
+		e.output().WriteByte('d')
+	}
+	// The closing brace
+	if _, err := jd.Token(); err != nil {
+		return err
+	}
+	return e.output().WriteByte('e')
+}
+
+// Writes the base64 value of a {"$bytes": "<base64>"} object as a string
+func (e *Encoder) writeJSONBytes(jd *json.Decoder) error {
+	token, err := jd.Token()
+	if err != nil {
+		return err
+	}
+	encoded, ok := token.(string)
+	if !ok {
+		return fmt.Errorf("%w: JSON %q value %v is not a string", ErrUnsupportedType, jsonBytesKey, token)
+	}
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	if token, err = jd.Token(); err != nil {
+		return err
+	} else if token != json.Delim('}') {
+		return fmt.Errorf("%w: JSON %q object with other keys", ErrUnsupportedType, jsonBytesKey)
+	}
+	return e.writeBytes(b)
+}
@@ -0,0 +1,77 @@
+package bencode_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+var (
+	jsonTestCases = map[string]string{
+		"i-42e":                            `-42`,
+		"i123456789012345678901234567890e": `123456789012345678901234567890`,
+		"2:42":                             `"42"`,
+		"0:":                               `""`,
+		"3:\x00\x01\xff":                   `{"$bytes":"AAH/"}`,
+		"le":                               `[]`,
+		"de":                               `{}`,
+		"li1e1:ae":                         `[1,"a"]`,
+		// Keys keep their order, even if unsorted
+		"d1:bi1e1:ali2eee": `{"b":1,"a":[2]}`,
+		// Keys that are not UTF-8 or start with "$" are escaped
+		"d2:\xff\x00i1e6:$bytes1:x2:$$0:e": `{"$bytes:/wA=":1,"$$bytes":"x","$$$":""}`,
+		"d6:pieces4:\x8f\x0c\x6b\x2ee":     `{"pieces":{"$bytes":"jwxrLg=="}}`,
+	}
+)
+
+func TestToJSON(t *testing.T) {
+	for test, expected := range jsonTestCases {
+		var out strings.Builder
+		if err := bencode.ToJSON(strings.NewReader(test), &out); err != nil {
+			t.Fatalf("ToJSON of %q returned error: %v", test, err)
+		}
+		if out.String() != expected+"\n" {
+			t.Fatalf("Expected %q for %q, got %q", expected+"\n", test, out.String())
+		}
+	}
+	for _, invalid := range []string{"", "e", "li1e", "di1ei1ee", "x"} {
+		var out strings.Builder
+		if err := bencode.ToJSON(strings.NewReader(invalid), &out); err == nil {
+			t.Fatalf("Expected ToJSON of %q to fail, got %q", invalid, out.String())
+		}
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	// FromJSON reverses ToJSON
+	for expected, test := range jsonTestCases {
+		var out strings.Builder
+		if err := bencode.FromJSON(strings.NewReader(test), &out); err != nil {
+			t.Fatalf("FromJSON of %q returned error: %v", test, err)
+		}
+		if out.String() != expected {
+			t.Fatalf("Expected %q for %q, got %q", expected, test, out.String())
+		}
+	}
+	// Whitespace and non-escaped "$" keys are accepted
+	var out strings.Builder
+	if err := bencode.FromJSON(strings.NewReader(` { "$x" : [ 1 , {"$bytes": ""} ] } `), &out); err != nil {
+		t.Fatalf("FromJSON returned error: %v", err)
+	}
+	if out.String() != "d2:$xli1e0:ee" {
+		t.Fatalf("Expected %q, got %q", "d2:$xli1e0:ee", out.String())
+	}
+	unsupported := []string{`true`, `null`, `1.5`, `1e3`, `[false]`, `{"$bytes":1}`, `{"$bytes":"","a":1}`}
+	for _, test := range unsupported {
+		if err := bencode.FromJSON(strings.NewReader(test), &out); !errors.Is(err, bencode.ErrUnsupportedType) {
+			t.Fatalf("Expected ErrUnsupportedType for %q, got %v", test, err)
+		}
+	}
+	for _, invalid := range []string{``, `[`, `{"a"}`, `{"$bytes":"!"}`, `{"$bytes:!":1}`, `1 garbage`, `1 2`, `[]]`} {
+		if err := bencode.FromJSON(strings.NewReader(invalid), &out); err == nil {
+			t.Fatalf("Expected FromJSON of %q to fail", invalid)
+		}
+	}
+}
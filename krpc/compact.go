@@ -0,0 +1,146 @@
+package krpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"net/netip"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+var (
+	// Error for a compact node list with a length that is not a multiple
+	// of the size of a node, or a node with an address of the wrong family
+	ErrInvalidNodes = errors.New("krpc: invalid compact nodes")
+	// Error for a compact peer that is neither 6 nor 18 bytes long
+	ErrInvalidPeer = errors.New("krpc: invalid compact peer")
+)
+
+const (
+	// The size of an IPv4 address and port
+	compactAddrSize = 4 + 2
+	// The size of an IPv6 address and port
+	compactAddr6Size = 16 + 2
+	// The size of an IPv4 node in a compact node list
+	compactNodeSize = IDSize + compactAddrSize
+	// The size of an IPv6 node in a compact node list
+	compactNode6Size = IDSize + compactAddr6Size
+)
+
+// A DHT node
+type NodeInfo struct {
+	ID   ID
+	Addr netip.AddrPort
+}
+
+// The IPv4 nodes of a response, encoded as a string of 26 bytes per node
+type CompactNodes []NodeInfo
+
+// The IPv6 nodes of a response, encoded as a string of 38 bytes per node
+type CompactNodes6 []NodeInfo
+
+// The peers of a get_peers response, encoded as a list of strings of
+// 6 bytes for IPv4 peers and 18 bytes for IPv6 peers
+type CompactPeers []netip.AddrPort
+
+// Returns the bencode encoding of the nodes
+func (n CompactNodes) MarshalBencode() ([]byte, error) {
+	return marshalNodes(n, compactNodeSize)
+}
+
+// Decodes a compact list of IPv4 nodes
+func (n *CompactNodes) UnmarshalBencode(data []byte) error {
+	nodes, err := unmarshalNodes(data, compactNodeSize)
+	*n = nodes
+	return err
+}
+
+// Returns the bencode encoding of the nodes
+func (n CompactNodes6) MarshalBencode() ([]byte, error) {
+	return marshalNodes(n, compactNode6Size)
+}
+
+// Decodes a compact list of IPv6 nodes
+func (n *CompactNodes6) UnmarshalBencode(data []byte) error {
+	nodes, err := unmarshalNodes(data, compactNode6Size)
+	*n = nodes
+	return err
+}
+
+// Returns the bencode encoding of the peers
+func (p CompactPeers) MarshalBencode() ([]byte, error) {
+	list := make([][]byte, len(p))
+	for i, peer := range p {
+		list[i] = appendAddr(nil, peer, !peer.Addr().Unmap().Is4())
+	}
+	return bencode.Marshal(list)
+}
+
+// Decodes a list of compact peers
+func (p *CompactPeers) UnmarshalBencode(data []byte) error {
+	list := [][]byte{}
+	if err := bencode.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	peers := make(CompactPeers, len(list))
+	for i, b := range list {
+		if len(b) != compactAddrSize && len(b) != compactAddr6Size {
+			return ErrInvalidPeer
+		}
+		peers[i] = parseAddr(b)
+	}
+	*p = peers
+	return nil
+}
+
+// Appends the compact form of an address: the IP address followed by the
+// port in network byte order
+func appendAddr(b []byte, addr netip.AddrPort, is6 bool) []byte {
+	if is6 {
+		ip := addr.Addr().As16()
+		b = append(b, ip[:]...)
+	} else {
+		ip := addr.Addr().Unmap().As4()
+		b = append(b, ip[:]...)
+	}
+	return binary.BigEndian.AppendUint16(b, addr.Port())
+}
+
+// Parses the compact form of an address of 6 or 18 bytes
+func parseAddr(b []byte) netip.AddrPort {
+	ip, _ := netip.AddrFromSlice(b[:len(b)-2])
+	return netip.AddrPortFrom(ip, binary.BigEndian.Uint16(b[len(b)-2:]))
+}
+
+// Encodes nodes as a compact string with nodes of a given size
+func marshalNodes(nodes []NodeInfo, size int) ([]byte, error) {
+	is6 := size == compactNode6Size
+	b := make([]byte, 0, len(nodes)*size)
+	for _, node := range nodes {
+		ip := node.Addr.Addr()
+		if is6 == ip.Unmap().Is4() {
+			return nil, ErrInvalidNodes
+		}
+		b = append(b, node.ID[:]...)
+		b = appendAddr(b, node.Addr, is6)
+	}
+	return bencode.Marshal(b)
+}
+
+// Decodes a compact string with nodes of a given size
+func unmarshalNodes(data []byte, size int) ([]NodeInfo, error) {
+	var b []byte
+	if err := bencode.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	if len(b)%size != 0 {
+		return nil, ErrInvalidNodes
+	}
+	nodes := make([]NodeInfo, 0, len(b)/size)
+	for ; len(b) > 0; b = b[size:] {
+		node := NodeInfo{Addr: parseAddr(b[IDSize:size])}
+		copy(node.ID[:], b)
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,86 @@
+package krpc_test
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+	"github.com/stefanovazzocell/bencode/krpc"
+)
+
+func TestCompactNodes(t *testing.T) {
+	nodes := krpc.CompactNodes{
+		{ID: nodeID, Addr: netip.MustParseAddrPort("1.2.3.4:6881")},
+		{ID: infoHash, Addr: netip.MustParseAddrPort("[::ffff:5.6.7.8]:80")},
+	}
+	data, err := bencode.Marshal(nodes)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	expected := "52:" + string(nodeID[:]) + "\x01\x02\x03\x04\x1a\xe1" + string(infoHash[:]) + "\x05\x06\x07\x08\x00\x50"
+	if string(data) != expected {
+		t.Fatalf("Expected %q, got %q", expected, data)
+	}
+	decoded := krpc.CompactNodes{}
+	if err := bencode.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	nodes[1].Addr = netip.MustParseAddrPort("5.6.7.8:80")
+	if !reflect.DeepEqual(decoded, nodes) {
+		t.Fatalf("Expected %+v, got %+v", nodes, decoded)
+	}
+	// IPv6 nodes
+	nodes6 := krpc.CompactNodes6{{ID: nodeID, Addr: netip.MustParseAddrPort("[2001:db8::1]:6881")}}
+	if data, err = bencode.Marshal(nodes6); err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if len(data) != len("38:")+38 {
+		t.Fatalf("Expected a 38 bytes node, got %q", data)
+	}
+	decoded6 := krpc.CompactNodes6{}
+	if err := bencode.Unmarshal(data, &decoded6); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded6, nodes6) {
+		t.Fatalf("Expected %+v, got %+v", nodes6, decoded6)
+	}
+	// Addresses of the wrong family
+	if _, err := bencode.Marshal(krpc.CompactNodes(nodes6)); err != krpc.ErrInvalidNodes {
+		t.Fatalf("Expected ErrInvalidNodes, got %v", err)
+	}
+	if _, err := bencode.Marshal(krpc.CompactNodes6(nodes)); err != krpc.ErrInvalidNodes {
+		t.Fatalf("Expected ErrInvalidNodes, got %v", err)
+	}
+	for _, invalid := range []string{"25:" + strings.Repeat("x", 25), "26:" + strings.Repeat("x", 26), "i1e"} {
+		if err := bencode.Unmarshal([]byte(invalid), &decoded6); err == nil {
+			t.Fatalf("Expected Unmarshal of %q to fail, got %+v", invalid, decoded6)
+		}
+	}
+}
+
+func TestCompactPeers(t *testing.T) {
+	peers := krpc.CompactPeers{
+		netip.MustParseAddrPort("1.2.3.4:6881"),
+		netip.MustParseAddrPort("[2001:db8::1]:80"),
+	}
+	data, err := bencode.Marshal(peers)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	expected := "l6:\x01\x02\x03\x04\x1a\xe118:\x20\x01\x0d\xb8" + strings.Repeat("\x00", 11) + "\x01\x00\x50e"
+	if string(data) != expected {
+		t.Fatalf("Expected %q, got %q", expected, data)
+	}
+	decoded := krpc.CompactPeers{}
+	if err := bencode.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, peers) {
+		t.Fatalf("Expected %+v, got %+v", peers, decoded)
+	}
+	if err := bencode.Unmarshal([]byte("l5:abcdee"), &decoded); err != krpc.ErrInvalidPeer {
+		t.Fatalf("Expected ErrInvalidPeer, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+// Package krpc encodes and decodes the KRPC messages of the Mainline DHT (BEP 5).
+//
+// Messages are decoded with limits on the nesting depth and the size of
+// the strings, so that hostile packets can't cause large allocations.
+package krpc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+var (
+	// Error for a message with an unknown type or without the dictionary
+	// required by its type
+	ErrInvalidMessage = errors.New("krpc: invalid message")
+)
+
+const (
+	// The maximum nesting depth of a message: the messages of BEP 5 are at
+	// most 3 levels deep ("r" > "values" > peer)
+	maxDepth = 4
+	// The size of a node ID or an info-hash
+	IDSize = 20
+)
+
+// The types of messages ("y")
+const (
+	TypeQuery    = "q"
+	TypeResponse = "r"
+	TypeError    = "e"
+)
+
+// The methods of queries ("q")
+const (
+	MethodPing         = "ping"
+	MethodFindNode     = "find_node"
+	MethodGetPeers     = "get_peers"
+	MethodAnnouncePeer = "announce_peer"
+)
+
+// The codes of error messages
+const (
+	ErrorGeneric       = 201
+	ErrorServer        = 202
+	ErrorProtocol      = 203
+	ErrorMethodUnknown = 204
+)
+
+// A node ID or an info-hash
+type ID [IDSize]byte
+
+// Returns the ID as a lowercase hex string
+func (id ID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// A KRPC message: a query, a response or an error, depending on Type
+type Message struct {
+	// The transaction ID chosen by the querying node
+	TransactionID []byte `bencode:"t"`
+	// The type of the message: TypeQuery, TypeResponse or TypeError
+	Type string `bencode:"y"`
+	// The method of a query
+	Method string `bencode:"q,omitempty"`
+	// The arguments of a query
+	Args *Args `bencode:"a,omitempty"`
+	// The return values of a response
+	Return *Return `bencode:"r,omitempty"`
+	// The error of an error message
+	Error *Error `bencode:"e,omitempty"`
+	// The client version, if any
+	Version []byte `bencode:"v,omitempty"`
+}
+
+// The arguments of a query
+type Args struct {
+	// The ID of the querying node
+	ID ID `bencode:"id"`
+	// The ID of the node to find with find_node
+	Target *ID `bencode:"target,omitempty"`
+	// The info-hash of get_peers and announce_peer
+	InfoHash *ID `bencode:"info_hash,omitempty"`
+	// The port of announce_peer
+	Port int `bencode:"port,omitempty"`
+	// If 1, announce_peer uses the source port of the packet instead of Port
+	ImpliedPort int `bencode:"implied_port,omitempty"`
+	// The token of announce_peer, from a previous get_peers response
+	Token []byte `bencode:"token,omitempty"`
+}
+
+// The return values of a response
+type Return struct {
+	// The ID of the responding node
+	ID ID `bencode:"id"`
+	// The closest IPv4 nodes of find_node and get_peers
+	Nodes CompactNodes `bencode:"nodes,omitempty"`
+	// The closest IPv6 nodes of find_node and get_peers (BEP 32)
+	Nodes6 CompactNodes6 `bencode:"nodes6,omitempty"`
+	// The peers of get_peers
+	Values CompactPeers `bencode:"values,omitempty"`
+	// The token of get_peers, for a later announce_peer
+	Token []byte `bencode:"token,omitempty"`
+}
+
+// The error of an error message, encoded as a list of a code and a message
+type Error struct {
+	Code    int
+	Message string
+}
+
+// Returns a description of the error
+func (e *Error) Error() string {
+	return fmt.Sprintf("krpc: error %d: %s", e.Code, e.Message)
+}
+
+// Returns the bencode encoding of the error
+func (e Error) MarshalBencode() ([]byte, error) {
+	return bencode.Marshal([]interface{}{e.Code, e.Message})
+}
+
+// Decodes an error from a list of a code and a message
+func (e *Error) UnmarshalBencode(data []byte) error {
+	d := bencode.NewParserFromBytes(data)
+	if tok, err := d.Token(); err != nil {
+		return err
+	} else if tok.Type != bencode.ListStart {
+		return ErrInvalidMessage
+	}
+	code, err := d.AsInt()
+	if err != nil {
+		return err
+	}
+	msg, err := d.AsString()
+	if err != nil {
+		return err
+	}
+	if tok, err := d.Token(); err != nil {
+		return err
+	} else if tok.Type != bencode.End {
+		return ErrInvalidMessage
+	}
+	*e = Error{Code: code, Message: msg}
+	return nil
+}
+
+// Returns a ping query
+func NewPing(transactionID []byte, id ID) *Message {
+	return newQuery(transactionID, MethodPing, &Args{ID: id})
+}
+
+// Returns a find_node query
+func NewFindNode(transactionID []byte, id, target ID) *Message {
+	return newQuery(transactionID, MethodFindNode, &Args{ID: id, Target: &target})
+}
+
+// Returns a get_peers query
+func NewGetPeers(transactionID []byte, id, infoHash ID) *Message {
+	return newQuery(transactionID, MethodGetPeers, &Args{ID: id, InfoHash: &infoHash})
+}
+
+// Returns an announce_peer query.
+// If impliedPort is true the receiver uses the source port of the packet.
+func NewAnnouncePeer(transactionID []byte, id, infoHash ID, port int, impliedPort bool, token []byte) *Message {
+	args := &Args{ID: id, InfoHash: &infoHash, Port: port, Token: token}
+	if impliedPort {
+		args.ImpliedPort = 1
+	}
+	return newQuery(transactionID, MethodAnnouncePeer, args)
+}
+
+// Returns a query
+func newQuery(transactionID []byte, method string, args *Args) *Message {
+	return &Message{
+		TransactionID: transactionID,
+		Type:          TypeQuery,
+		Method:        method,
+		Args:          args,
+	}
+}
+
+// Returns a response to a query
+func NewResponse(transactionID []byte, ret *Return) *Message {
+	return &Message{
+		TransactionID: transactionID,
+		Type:          TypeResponse,
+		Return:        ret,
+	}
+}
+
+// Returns an error in response to a query
+func NewError(transactionID []byte, code int, message string) *Message {
+	return &Message{
+		TransactionID: transactionID,
+		Type:          TypeError,
+		Error:         &Error{Code: code, Message: message},
+	}
+}
+
+// Returns the bencode encoding of the message
+func (m *Message) Encode() ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return bencode.Marshal(m)
+}
+
+// Decodes a message from a packet
+func Decode(packet []byte) (*Message, error) {
+	d := bencode.NewParserFromBytes(packet,
+		bencode.WithMaxDepth(maxDepth),
+		bencode.WithMaxTotalBytes(len(packet)),
+	)
+	m := new(Message)
+	if err := d.Decode(m); err != nil {
+		return nil, err
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Checks that the message has the dictionary required by its type
+func (m *Message) validate() error {
+	switch m.Type {
+	case TypeQuery:
+		if m.Method != "" && m.Args != nil {
+			return nil
+		}
+	case TypeResponse:
+		if m.Return != nil {
+			return nil
+		}
+	case TypeError:
+		if m.Error != nil {
+			return nil
+		}
+	}
+	return ErrInvalidMessage
+}
@@ -0,0 +1,123 @@
+package krpc_test
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+	"github.com/stefanovazzocell/bencode/krpc"
+)
+
+var (
+	nodeID   = krpc.ID([]byte(strings.Repeat("a", 20)))
+	infoHash = krpc.ID([]byte(strings.Repeat("h", 20)))
+	// Messages from BEP 5
+	messageTestCases = map[string]*krpc.Message{
+		"d1:ad2:id20:aaaaaaaaaaaaaaaaaaaae1:q4:ping1:t2:aa1:y1:qe": krpc.NewPing([]byte("aa"), nodeID),
+		"d1:ad2:id20:aaaaaaaaaaaaaaaaaaaa6:target20:hhhhhhhhhhhhhhhhhhhhe1:q9:find_node1:t2:aa1:y1:qe": krpc.NewFindNode(
+			[]byte("aa"), nodeID, infoHash),
+		"d1:ad2:id20:aaaaaaaaaaaaaaaaaaaa9:info_hash20:hhhhhhhhhhhhhhhhhhhhe1:q9:get_peers1:t2:aa1:y1:qe": krpc.NewGetPeers(
+			[]byte("aa"), nodeID, infoHash),
+		"d1:ad2:id20:aaaaaaaaaaaaaaaaaaaa12:implied_porti1e9:info_hash20:hhhhhhhhhhhhhhhhhhhh4:porti6881e5:token8:aoeusnthe1:q13:announce_peer1:t2:aa1:y1:qe": krpc.NewAnnouncePeer(
+			[]byte("aa"), nodeID, infoHash, 6881, true, []byte("aoeusnth")),
+		"d1:rd2:id20:aaaaaaaaaaaaaaaaaaaae1:t2:aa1:y1:re": krpc.NewResponse(
+			[]byte("aa"), &krpc.Return{ID: nodeID}),
+		"d1:rd2:id20:aaaaaaaaaaaaaaaaaaaa5:token8:aoeusnth6:valuesl6:axje.u6:idhtnmee1:t2:aa1:y1:re": krpc.NewResponse([]byte("aa"), &krpc.Return{
+			ID:     nodeID,
+			Token:  []byte("aoeusnth"),
+			Values: krpc.CompactPeers{netip.MustParseAddrPort("97.120.106.101:11893"), netip.MustParseAddrPort("105.100.104.116:28269")},
+		}),
+		"d1:eli201e23:A Generic Error Ocurrede1:t2:aa1:y1:ee": krpc.NewError(
+			[]byte("aa"), krpc.ErrorGeneric, "A Generic Error Ocurred"),
+	}
+	invalidMessages = []string{
+		"",
+		"le",
+		"d1:t2:aa1:y1:xe",
+		"d1:t2:aa1:y1:qe",
+		"d1:ad2:id20:aaaaaaaaaaaaaaaaaaaae1:t2:aa1:y1:qe",
+		"d1:t2:aa1:y1:re",
+		"d1:rd2:id3:abce1:t2:aa1:y1:re",
+		"d1:rd2:id20:aaaaaaaaaaaaaaaaaaaa5:nodes3:abce1:t2:aa1:y1:re",
+		"d1:rd2:id20:aaaaaaaaaaaaaaaaaaaa6:valuesl3:abcee1:t2:aa1:y1:re",
+		"d1:eli201ee1:t2:aa1:y1:ee",
+		"d1:eli201e1:a1:bee1:t2:aa1:y1:ee",
+		"d1:ed1:ai1ee1:t2:aa1:y1:ee",
+		"d1:t2:aa1:y1:e1:xlllllleeeeeee",
+		"d1:t4000000000:aa1:y1:ee",
+	}
+)
+
+func TestMessages(t *testing.T) {
+	for packet, expected := range messageTestCases {
+		m, err := krpc.Decode([]byte(packet))
+		if err != nil {
+			t.Fatalf("Decode of %q returned error: %v", packet, err)
+		}
+		if !reflect.DeepEqual(m, expected) {
+			t.Fatalf("Expected %+v, got %+v", expected, m)
+		}
+		encoded, err := expected.Encode()
+		if err != nil {
+			t.Fatalf("Encode of %+v returned error: %v", expected, err)
+		}
+		if string(encoded) != packet {
+			t.Fatalf("Expected %q, got %q", packet, encoded)
+		}
+	}
+	// Unknown keys are ignored
+	m, err := krpc.Decode([]byte("d1:ad2:id20:aaaaaaaaaaaaaaaaaaaa1:xi1ee1:q4:ping1:t2:aa1:v4:UT011:y1:qe"))
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	if m.Method != krpc.MethodPing || m.Args.ID != nodeID || string(m.Version) != "UT01" {
+		t.Fatalf("Unexpected message %+v", m)
+	}
+	if _, err := (&krpc.Message{Type: krpc.TypeQuery}).Encode(); err != krpc.ErrInvalidMessage {
+		t.Fatalf("Expected ErrInvalidMessage, got %v", err)
+	}
+}
+
+func TestInvalidMessages(t *testing.T) {
+	for _, packet := range invalidMessages {
+		if m, err := krpc.Decode([]byte(packet)); err == nil {
+			t.Fatalf("Expected Decode of %q to fail, got %+v", packet, m)
+		}
+	}
+	if _, err := krpc.Decode([]byte("d1:t2:aa1:y1:e1:xlllllleeeeeee")); !errors.Is(err, bencode.ErrMaxDepth) {
+		t.Fatalf("Expected ErrMaxDepth, got %v", err)
+	}
+}
+
+func TestError(t *testing.T) {
+	m, err := krpc.Decode([]byte("d1:eli204e14:Method Unknowne1:t2:aa1:y1:ee"))
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	var krpcErr error = m.Error
+	expected := "krpc: error 204: Method Unknown"
+	if krpcErr.Error() != expected || m.Error.Code != krpc.ErrorMethodUnknown {
+		t.Fatalf("Expected %q, got %v", expected, krpcErr)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for packet := range messageTestCases {
+		f.Add([]byte(packet))
+	}
+	for _, packet := range invalidMessages {
+		f.Add([]byte(packet))
+	}
+	f.Fuzz(func(t *testing.T, packet []byte) {
+		m, err := krpc.Decode(packet)
+		if err != nil {
+			return
+		}
+		if _, err := m.Encode(); err != nil {
+			t.Fatalf("Encode of decoded %q returned error: %v", packet, err)
+		}
+	})
+}
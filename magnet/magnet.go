@@ -0,0 +1,252 @@
+// Package magnet parses and generates magnet URIs for BitTorrent (BEP 9).
+package magnet
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stefanovazzocell/bencode/metainfo"
+)
+
+var (
+	// Error for a URI that is not a magnet link
+	ErrInvalidMagnet = errors.New("magnet: invalid magnet URI")
+	// Error for an exact topic with a malformed info-hash
+	ErrInvalidInfoHash = errors.New("magnet: invalid info-hash")
+	// Error for a magnet link without a v1 or v2 info-hash
+	ErrNoInfoHash = errors.New("magnet: missing info-hash")
+	// Error for a malformed select-only list such as "1-"
+	ErrInvalidSelect = errors.New("magnet: invalid select-only list")
+)
+
+const (
+	// The exact topic prefix of a v1 info-hash
+	btihPrefix = "urn:btih:"
+	// The exact topic prefix of a v2 info-hash
+	btmhPrefix = "urn:btmh:"
+	// The multihash prefix of a SHA-256 hash: the sha2-256 code and length
+	sha256Multihash = "1220"
+)
+
+// A range of file indices for "so", from First to Last included
+type FileRange struct {
+	First, Last int
+}
+
+// The content of a magnet link
+type Magnet struct {
+	// The v1 info-hash (btih), zero if not set
+	InfoHash metainfo.Hash
+	// The v2 info-hash (btmh), zero if not set
+	InfoHashV2 metainfo.HashV2
+	// The display name (dn)
+	DisplayName string
+	// The tracker URLs (tr)
+	Trackers []string
+	// The web seed URLs (ws, BEP 19)
+	WebSeeds []string
+	// The peer addresses as host:port (x.pe)
+	Peers []string
+	// The indices of the files to download (so, BEP 53)
+	SelectOnly []FileRange
+}
+
+// Returns true if the magnet link has a v1 info-hash
+func (m *Magnet) HasInfoHash() bool {
+	return m.InfoHash != metainfo.Hash{}
+}
+
+// Returns true if the magnet link has a v2 info-hash
+func (m *Magnet) HasInfoHashV2() bool {
+	return m.InfoHashV2 != metainfo.HashV2{}
+}
+
+// Parses a magnet URI.
+// At least one v1 (hex or base32) or v2 info-hash is required.
+func Parse(uri string) (*Magnet, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "magnet" {
+		return nil, ErrInvalidMagnet
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, ErrInvalidMagnet
+	}
+	// Sort the keys to keep numbered parameters such as "tr.1" in order
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		iName, iNumber := paramOrder(keys[i])
+		jName, jNumber := paramOrder(keys[j])
+		if iName != jName {
+			return iName < jName
+		}
+		if iNumber != jNumber {
+			return iNumber < jNumber
+		}
+		return keys[i] < keys[j]
+	})
+	m := new(Magnet)
+	for _, key := range keys {
+		values := query[key]
+		name, _, _ := strings.Cut(key, ".")
+		if key == "x.pe" {
+			name = key
+		}
+		switch name {
+		case "xt":
+			for _, xt := range values {
+				if err := m.parseTopic(xt); err != nil {
+					return nil, err
+				}
+			}
+		case "dn":
+			m.DisplayName = values[0]
+		case "tr":
+			m.Trackers = append(m.Trackers, values...)
+		case "ws":
+			m.WebSeeds = append(m.WebSeeds, values...)
+		case "x.pe":
+			m.Peers = append(m.Peers, values...)
+		case "so":
+			for _, so := range values {
+				ranges, err := parseSelect(so)
+				if err != nil {
+					return nil, err
+				}
+				m.SelectOnly = append(m.SelectOnly, ranges...)
+			}
+		}
+	}
+	if !m.HasInfoHash() && !m.HasInfoHashV2() {
+		return nil, ErrNoInfoHash
+	}
+	return m, nil
+}
+
+// Returns the name and number of a parameter such as "tr.10", or the key
+// and -1 if it is not numbered
+func paramOrder(key string) (string, int) {
+	if name, suffix, ok := strings.Cut(key, "."); ok {
+		if n, err := strconv.Atoi(suffix); err == nil && n >= 0 {
+			return name, n
+		}
+	}
+	return key, -1
+}
+
+// Parses an exact topic, ignoring the ones that are not info-hashes
+func (m *Magnet) parseTopic(xt string) error {
+	if hash, ok := strings.CutPrefix(xt, btihPrefix); ok {
+		var decoded []byte
+		var err error
+		switch len(hash) {
+		case hex.EncodedLen(metainfo.HashSize):
+			decoded, err = hex.DecodeString(hash)
+		case base32.StdEncoding.EncodedLen(metainfo.HashSize):
+			decoded, err = base32.StdEncoding.DecodeString(strings.ToUpper(hash))
+		default:
+			return ErrInvalidInfoHash
+		}
+		if err != nil {
+			return ErrInvalidInfoHash
+		}
+		copy(m.InfoHash[:], decoded)
+		return nil
+	}
+	if hash, ok := strings.CutPrefix(xt, btmhPrefix); ok {
+		hash, ok = strings.CutPrefix(hash, sha256Multihash)
+		if !ok || len(hash) != hex.EncodedLen(metainfo.HashSizeV2) {
+			return ErrInvalidInfoHash
+		}
+		decoded, err := hex.DecodeString(hash)
+		if err != nil {
+			return ErrInvalidInfoHash
+		}
+		copy(m.InfoHashV2[:], decoded)
+	}
+	return nil
+}
+
+// Parses a select-only list such as "0,2,4-6"
+func parseSelect(so string) ([]FileRange, error) {
+	ranges := []FileRange{}
+	for _, part := range strings.Split(so, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		r := FileRange{}
+		var err error
+		if r.First, err = strconv.Atoi(first); err != nil || r.First < 0 {
+			return nil, ErrInvalidSelect
+		}
+		r.Last = r.First
+		if isRange {
+			if r.Last, err = strconv.Atoi(last); err != nil || r.Last < r.First {
+				return nil, ErrInvalidSelect
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// Returns the magnet link for a torrent, with its info-hashes, name and trackers
+func FromMetaInfo(mi *metainfo.MetaInfo) (*Magnet, error) {
+	info, err := mi.Info()
+	if err != nil {
+		return nil, err
+	}
+	m := &Magnet{
+		DisplayName: info.Name,
+	}
+	if info.HasV1() || !info.HasV2() {
+		m.InfoHash = mi.InfoHash()
+	}
+	if info.HasV2() {
+		m.InfoHashV2 = mi.InfoHashV2()
+	}
+	for _, tier := range mi.AnnounceTiers() {
+		m.Trackers = append(m.Trackers, tier...)
+	}
+	return m, nil
+}
+
+// Returns the magnet URI
+func (m *Magnet) String() string {
+	params := []string{}
+	if m.HasInfoHash() {
+		params = append(params, "xt="+btihPrefix+m.InfoHash.String())
+	}
+	if m.HasInfoHashV2() {
+		params = append(params, "xt="+btmhPrefix+sha256Multihash+m.InfoHashV2.String())
+	}
+	if m.DisplayName != "" {
+		params = append(params, "dn="+url.QueryEscape(m.DisplayName))
+	}
+	for _, tr := range m.Trackers {
+		params = append(params, "tr="+url.QueryEscape(tr))
+	}
+	for _, ws := range m.WebSeeds {
+		params = append(params, "ws="+url.QueryEscape(ws))
+	}
+	for _, peer := range m.Peers {
+		params = append(params, "x.pe="+url.QueryEscape(peer))
+	}
+	if len(m.SelectOnly) > 0 {
+		ranges := make([]string, len(m.SelectOnly))
+		for i, r := range m.SelectOnly {
+			ranges[i] = strconv.Itoa(r.First)
+			if r.Last != r.First {
+				ranges[i] += "-" + strconv.Itoa(r.Last)
+			}
+		}
+		params = append(params, "so="+strings.Join(ranges, ","))
+	}
+	return "magnet:?" + strings.Join(params, "&")
+}
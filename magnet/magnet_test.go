@@ -0,0 +1,157 @@
+package magnet_test
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode/magnet"
+	"github.com/stefanovazzocell/bencode/metainfo"
+)
+
+const (
+	fedoraMagnet = "magnet:?xt=urn:btih:LTLWT6I2S4R2DBLMR4YPFJBVU4LCOBMY&dn=Fedora-Workstation-Live-x86_64-38&tr=http%3A%2F%2Ftorrent.fedoraproject.org%3A6969%2Fannounce"
+	fedoraHash   = "5cd769f91a9723a1856c8f30f2a435a716270598"
+	testHashV2   = "d8dd32ac93357c368556af3ac1d95c9d76bd0dff6fa9833ecdac3d53134efabb"
+)
+
+var (
+	parseTestCases = map[string]magnet.Magnet{
+		fedoraMagnet: {
+			DisplayName: "Fedora-Workstation-Live-x86_64-38",
+			Trackers:    []string{"http://torrent.fedoraproject.org:6969/announce"},
+		},
+		"magnet:?xt=urn:btih:" + fedoraHash + "&tr.2=udp://b&tr.1=udp://a&ws=http://seed/&x.pe=10.0.0.1:6881&so=0,2,4-6": {
+			Trackers:   []string{"udp://a", "udp://b"},
+			WebSeeds:   []string{"http://seed/"},
+			Peers:      []string{"10.0.0.1:6881"},
+			SelectOnly: []magnet.FileRange{{0, 0}, {2, 2}, {4, 6}},
+		},
+		"magnet:?xt=urn:btih:" + strings.ToLower("LTLWT6I2S4R2DBLMR4YPFJBVU4LCOBMY") + "&xt=urn:ed2k:ignored": {},
+	}
+	invalidMagnets = []string{
+		"",
+		"http://example.com/?xt=urn:btih:" + fedoraHash,
+		"magnet:?dn=missing",
+		"magnet:?xt=urn:btih:abc",
+		"magnet:?xt=urn:btih:" + strings.Repeat("z", 40),
+		"magnet:?xt=urn:btmh:1114" + testHashV2[:40],
+		"magnet:?xt=urn:btmh:1220" + testHashV2[:62],
+		"magnet:?xt=urn:btih:" + fedoraHash + "&so=1-",
+		"magnet:?xt=urn:btih:" + fedoraHash + "&so=3-1",
+		"magnet:?xt=urn:btih:" + fedoraHash + "&so=-1",
+		"magnet:?xt=urn:btih:" + fedoraHash + "&dn=%zz",
+	}
+)
+
+func TestParse(t *testing.T) {
+	for uri, expected := range parseTestCases {
+		m, err := magnet.Parse(uri)
+		if err != nil {
+			t.Fatalf("Parse of %q returned error: %v", uri, err)
+		}
+		if m.InfoHash.String() != fedoraHash || m.HasInfoHashV2() {
+			t.Fatalf("Unexpected info-hashes %v and %v for %q", m.InfoHash, m.InfoHashV2, uri)
+		}
+		expected.InfoHash = m.InfoHash
+		if !reflect.DeepEqual(*m, expected) {
+			t.Fatalf("Expected %+v, got %+v", expected, *m)
+		}
+		// The generated URI parses to the same magnet
+		again, err := magnet.Parse(m.String())
+		if err != nil {
+			t.Fatalf("Parse of %q returned error: %v", m.String(), err)
+		}
+		if !reflect.DeepEqual(again, m) {
+			t.Fatalf("Expected %+v after a round trip, got %+v", m, again)
+		}
+	}
+	// v2 info-hashes
+	m, err := magnet.Parse("magnet:?xt=urn:btmh:1220" + testHashV2 + "&dn=a+b")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if m.HasInfoHash() || m.InfoHashV2.String() != testHashV2 || m.DisplayName != "a b" {
+		t.Fatalf("Unexpected v2 magnet %+v", m)
+	}
+	for _, invalid := range invalidMagnets {
+		if m, err := magnet.Parse(invalid); err == nil {
+			t.Fatalf("Expected Parse of %q to fail, got %+v", invalid, m)
+		}
+	}
+}
+
+func TestNumberedTrackers(t *testing.T) {
+	// Numbered parameters are sorted by number, after the unnumbered ones
+	uri := "magnet:?xt=urn:btih:" + fedoraHash + "&tr=t0"
+	expected := []string{"t0"}
+	for i := 12; i >= 1; i-- {
+		uri += "&tr." + strconv.Itoa(i) + "=t" + strconv.Itoa(i)
+	}
+	for i := 1; i <= 12; i++ {
+		expected = append(expected, "t"+strconv.Itoa(i))
+	}
+	m, err := magnet.Parse(uri)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if !reflect.DeepEqual(m.Trackers, expected) {
+		t.Fatalf("Expected trackers %v, got %v", expected, m.Trackers)
+	}
+}
+
+func TestString(t *testing.T) {
+	m, err := magnet.Parse(fedoraMagnet)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	m.SelectOnly = []magnet.FileRange{{1, 1}, {3, 5}}
+	expected := "magnet:?xt=urn:btih:" + fedoraHash + "&dn=Fedora-Workstation-Live-x86_64-38&tr=http%3A%2F%2Ftorrent.fedoraproject.org%3A6969%2Fannounce&so=1,3-5"
+	if uri := m.String(); uri != expected {
+		t.Fatalf("Expected %q, got %q", expected, uri)
+	}
+}
+
+func TestFromMetaInfo(t *testing.T) {
+	info := "d6:lengthi1e4:name5:a b.c12:piece lengthi16384e6:pieces20:" + strings.Repeat("x", 20) + "e"
+	torrent := "d13:announce-listll9:http://a/el9:http://b/ee4:info" + info + "e"
+	mi, err := metainfo.Load(strings.NewReader(torrent))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	m, err := magnet.FromMetaInfo(mi)
+	if err != nil {
+		t.Fatalf("FromMetaInfo returned error: %v", err)
+	}
+	hash := sha1.Sum([]byte(info))
+	expected := "magnet:?xt=urn:btih:" + metainfo.Hash(hash).String() + "&dn=a+b.c&tr=http%3A%2F%2Fa%2F&tr=http%3A%2F%2Fb%2F"
+	if uri := m.String(); uri != expected {
+		t.Fatalf("Expected %q, got %q", expected, uri)
+	}
+	// Hybrid torrents have both info-hashes
+	hybrid := metainfo.MetaInfo{}
+	if err := hybrid.SetInfo(&metainfo.Info{
+		Name:        "x",
+		PieceLength: 16384,
+		Pieces:      []byte(strings.Repeat("x", 20)),
+		Length:      1,
+		MetaVersion: 2,
+		FileTree: &metainfo.FileTree{Dir: map[string]*metainfo.FileTree{
+			"x": {File: &metainfo.FileTreeFile{Length: 1, PiecesRoot: []byte(strings.Repeat("r", 32))}},
+		}},
+	}); err != nil {
+		t.Fatalf("SetInfo returned error: %v", err)
+	}
+	if m, err = magnet.FromMetaInfo(&hybrid); err != nil {
+		t.Fatalf("FromMetaInfo returned error: %v", err)
+	}
+	if m.InfoHash != sha1.Sum(hybrid.InfoBytes) || m.InfoHashV2 != sha256.Sum256(hybrid.InfoBytes) {
+		t.Fatalf("Expected both info-hashes, got %+v", m)
+	}
+	if _, err := magnet.FromMetaInfo(&metainfo.MetaInfo{}); err != metainfo.ErrNoInfo {
+		t.Fatalf("Expected ErrNoInfo, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package bencode
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+)
+
+var (
+	// Error for when a value can't be represented in bencode
+	ErrUnsupportedType = errors.New("unsupported type")
+)
+
+// The type of big.Int, which is encoded as an integer rather than a struct
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// Returns the bencode encoding of v.
+//
+// Structs are encoded as dictionaries using the field name or the name
+// from a `bencode:"name,omitempty"` tag as the key, maps with string keys
+// are encoded as dictionaries, slices and arrays as lists, []byte and byte
+// arrays as strings and big.Int as an integer. Pointers and interfaces are
+// encoded as the value they point to; nil struct fields are omitted.
+// Values implementing Marshaler, such as RawMessage, are written as
+// returned by MarshalBencode.
+func Marshal(v interface{}) ([]byte, error) {
+	e := new(Encoder)
+	if err := e.Encode(v); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// Writes the bencode encoding of v to the encoder output.
+// On error the output might contain a partial encoding.
+// See Marshal for details.
+func (e *Encoder) Encode(v interface{}) error {
+	if err := e.writeValue(reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+// Returns true if the value is considered empty for omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// Writes a reflected value to the encoder output
+func (e *Encoder) writeValue(v reflect.Value) error {
+	if v.IsValid() && !((v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil()) {
+		if v.Type().Implements(marshalerType) {
+			return e.writeMarshaler(v.Interface().(Marshaler))
+		}
+		if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(marshalerType) {
+			return e.writeMarshaler(v.Addr().Interface().(Marshaler))
+		}
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		return fmt.Errorf("%w: nil", ErrUnsupportedType)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return fmt.Errorf("%w: nil %s", ErrUnsupportedType, v.Type())
+		}
+		return e.writeValue(v.Elem())
+	case reflect.String:
+		return e.writeString(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.writeUint(v.Uint())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return e.writeBytes(v.Bytes())
+		}
+		return e.writeList(v)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return e.writeBytes(b)
+		}
+		return e.writeList(v)
+	case reflect.Map:
+		return e.writeReflectMap(v)
+	case reflect.Struct:
+		if v.Type() == bigIntType {
+			if v.CanAddr() {
+				return e.writeBigInt(v.Addr().Interface().(*big.Int))
+			}
+			n := v.Interface().(big.Int)
+			return e.writeBigInt(&n)
+		}
+		return e.writeStruct(v)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+}
+
+// Writes a reflected slice or array to the encoder output
+func (e *Encoder) writeList(v reflect.Value) error {
+	w := e.output()
+	w.WriteByte('l')
+	for i := 0; i < v.Len(); i++ {
+		if err := e.writeValue(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('e')
+}
+
+// Writes a reflected map to the encoder output with sorted keys
+func (e *Encoder) writeReflectMap(v reflect.Value) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	w := e.output()
+	w.WriteByte('d')
+	for _, k := range keys {
+		if err := e.writeString(k.String()); err != nil {
+			return err
+		}
+		if err := e.writeValue(v.MapIndex(k)); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('e')
+}
+
+// Writes a reflected struct to the encoder output
+func (e *Encoder) writeStruct(v reflect.Value) error {
+	w := e.output()
+	w.WriteByte('d')
+	for _, f := range cachedFields(v.Type()).list {
+		fv := v.FieldByIndex(f.index)
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil() {
+			continue
+		}
+		if err := e.writeString(f.name); err != nil {
+			return err
+		}
+		if err := e.writeValue(fv); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('e')
+}
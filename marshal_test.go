@@ -0,0 +1,134 @@
+package bencode_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+type testFile struct {
+	Length int      `bencode:"length"`
+	Path   []string `bencode:"path"`
+}
+
+type testInfo struct {
+	Name        string     `bencode:"name"`
+	PieceLength int64      `bencode:"piece length"`
+	Length      int        `bencode:"length,omitempty"`
+	Files       []testFile `bencode:"files,omitempty"`
+	Private     *uint8     `bencode:"private,omitempty"`
+}
+
+type testTorrent struct {
+	Announce     string     `bencode:"announce"`
+	AnnounceList [][]string `bencode:"announce-list,omitempty"`
+	Comment      string     `bencode:"comment,omitempty"`
+	Info         *testInfo  `bencode:"info"`
+	Ignored      string     `bencode:"-"`
+	unexported   string
+}
+
+type testEmbedded struct {
+	testFile
+	Name  string
+	Bytes []byte        `bencode:"bytes"`
+	Array [3]byte       `bencode:"array"`
+	Extra yesNoMap      `bencode:"extra,omitempty"`
+	Any   []interface{} `bencode:"any,omitempty"`
+}
+
+type yesNoMap map[string]string
+
+// Returns a big.Int from a base 10 number
+func bigInt(number string) *big.Int {
+	n, ok := new(big.Int).SetString(number, 10)
+	if !ok {
+		panic("invalid number " + number)
+	}
+	return n
+}
+
+var (
+	debianTorrentStruct = testTorrent{
+		Announce: "udp://tracker.publicbt.com:80/announce",
+		AnnounceList: [][]string{
+			{"udp://tracker.publicbt.com:80/announce"},
+			{"udp://tracker.openbittorrent.com:80/announce"},
+		},
+		Comment: "Debian CD from cdimage.debian.org",
+		Info: &testInfo{
+			Name:        "debian-8.8.0-arm64-netinst.iso",
+			Length:      170917888,
+			PieceLength: 262144,
+		},
+	}
+	marshalTestCases = map[string]interface{}{
+		"i-3e":           -3,
+		"5:hello":        "hello",
+		"5:bytes":        []byte("bytes"),
+		"3:abc":          [3]byte{'a', 'b', 'c'},
+		"li1ei2ee":       []int{1, 2},
+		"le":             []string(nil),
+		"d1:ai1e1:bi2ee": map[string]uint{"b": 2, "a": 1},
+		"d1:ai1e1:b1:xe": map[string]interface{}{"b": "x", "a": 1},
+		"d4:infodee": struct {
+			Info map[string]int `bencode:"info"`
+		}{},
+		debianTorrentSorted:                 debianTorrentStruct,
+		"i123456789012345678901234567890e":  bigInt("123456789012345678901234567890"),
+		"i-123456789012345678901234567890e": *bigInt("-123456789012345678901234567890"),
+		"d1:ni5ee": struct {
+			N *big.Int `bencode:"n"`
+		}{big.NewInt(5)},
+		"d4:Name3:Bob5:array3:\x00\x01\x025:bytes0:6:lengthi7e4:pathl1:aee": testEmbedded{
+			testFile: testFile{Length: 7, Path: []string{"a"}},
+			Name:     "Bob",
+			Array:    [3]byte{0, 1, 2},
+		},
+	}
+	invalidMarshalTestCases = []interface{}{
+		nil,
+		true,
+		1.5,
+		map[int]string{1: "a"},
+		[]interface{}{nil},
+		[]bool{true},
+		struct{ F func() }{func() {}},
+		(*big.Int)(nil),
+	}
+)
+
+func TestMarshal(t *testing.T) {
+	for expected, v := range marshalTestCases {
+		t.Logf("Test case (%T): %v", v, v)
+		actual, err := bencode.Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal returned error: %v", err)
+		}
+		if string(actual) != expected {
+			t.Fatalf("Expected %q, but got %q", expected, actual)
+		}
+		// Pointers should marshal the same way
+		actual, err = bencode.Marshal(&v)
+		if err != nil {
+			t.Fatalf("Marshal of pointer returned error: %v", err)
+		}
+		if string(actual) != expected {
+			t.Fatalf("Expected %q from pointer, but got %q", expected, actual)
+		}
+	}
+	for _, v := range invalidMarshalTestCases {
+		t.Logf("Invalid test case (%T): %v", v, v)
+		if actual, err := bencode.Marshal(v); !errors.Is(err, bencode.ErrUnsupportedType) {
+			t.Fatalf("Expected ErrUnsupportedType, but got (%q, %v)", actual, err)
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		bencode.Marshal(debianTorrentStruct)
+	}
+}
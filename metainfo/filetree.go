@@ -0,0 +1,170 @@
+package metainfo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+var (
+	// Error for a v2 torrent without a valid file tree, such as one with a
+	// node that mixes file attributes and entries or a pieces root that is
+	// not a SHA-256 hash
+	ErrInvalidFileTree = errors.New("metainfo: invalid file tree")
+)
+
+// The size of a SHA-256 hash, used for the v2 info-hash and pieces roots
+const HashSizeV2 = sha256.Size
+
+// A SHA-256 hash, such as the v2 info-hash of a torrent
+type HashV2 [HashSizeV2]byte
+
+// Returns the hash as a lowercase hex string
+func (h HashV2) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// A node of a BEP 52 file tree, either a file or a directory.
+//
+// Files are encoded as a dictionary with a single empty key holding their
+// attributes, directories as a dictionary of their entries by name.
+type FileTree struct {
+	// The attributes of a file, nil for directories
+	File *FileTreeFile
+	// The entries of a directory by name
+	Dir map[string]*FileTree
+}
+
+// The attributes of a file in a file tree
+type FileTreeFile struct {
+	Length int64 `bencode:"length"`
+	// The root of the merkle tree of the file, omitted for empty files
+	PiecesRoot []byte `bencode:"pieces root,omitempty"`
+}
+
+// A file of a file tree along with its path
+type TreeFile struct {
+	Path []string
+	FileTreeFile
+}
+
+// Returns the bencode encoding of the node
+func (ft FileTree) MarshalBencode() ([]byte, error) {
+	if ft.File != nil {
+		return bencode.Marshal(map[string]*FileTreeFile{"": ft.File})
+	}
+	if ft.Dir == nil {
+		return []byte("de"), nil
+	}
+	return bencode.Marshal(ft.Dir)
+}
+
+// Decodes a node and its entries.
+//
+// The data was already checked by the decoder of the enclosing value with
+// its options, so the whole tree is read in a single pass with a new one.
+func (ft *FileTree) UnmarshalBencode(data []byte) error {
+	return ft.decode(bencode.NewParserFromBytes(data))
+}
+
+// Reads a node and its entries from the decoder
+func (ft *FileTree) decode(d *bencode.Decoder) error {
+	token, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if token.Type != bencode.DictStart {
+		return ErrInvalidFileTree
+	}
+	dir := map[string]*FileTree{}
+	for {
+		if next, err := d.Next(); err != nil {
+			return err
+		} else if next == bencode.End {
+			break
+		}
+		key, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if key.String == "" {
+			// A file, which has no other entries
+			if len(dir) != 0 {
+				return ErrInvalidFileTree
+			}
+			return ft.decodeFile(d)
+		}
+		child := new(FileTree)
+		if err := child.decode(d); err != nil {
+			return err
+		}
+		dir[key.String] = child
+	}
+	// The end of the directory
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	*ft = FileTree{Dir: dir}
+	return nil
+}
+
+// Reads the attributes of a file and the end of its node from the decoder
+func (ft *FileTree) decodeFile(d *bencode.Decoder) error {
+	file := new(FileTreeFile)
+	if err := d.Decode(file); err != nil {
+		return err
+	}
+	if file.PiecesRoot != nil && len(file.PiecesRoot) != HashSizeV2 {
+		return ErrInvalidFileTree
+	}
+	if token, err := d.Token(); err != nil {
+		return err
+	} else if token.Type != bencode.End {
+		return ErrInvalidFileTree
+	}
+	*ft = FileTree{File: file}
+	return nil
+}
+
+// Returns the files of the tree sorted by path
+func (ft *FileTree) Files() []TreeFile {
+	files := []TreeFile{}
+	var walk func(node *FileTree, path []string)
+	walk = func(node *FileTree, path []string) {
+		if node.File != nil {
+			files = append(files, TreeFile{
+				Path:         append([]string{}, path...),
+				FileTreeFile: *node.File,
+			})
+			return
+		}
+		names := make([]string, 0, len(node.Dir))
+		for name := range node.Dir {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			walk(node.Dir[name], append(path, name))
+		}
+	}
+	walk(ft, nil)
+	return files
+}
+
+// Returns the total length of the files of the tree
+func (ft *FileTree) TotalLength() int64 {
+	total := int64(0)
+	for _, f := range ft.Files() {
+		total += f.Length
+	}
+	return total
+}
+
+// Returns the SHA-256 hash of the original bytes of the info dictionary,
+// which identifies v2 and hybrid torrents
+func (mi *MetaInfo) InfoHashV2() HashV2 {
+	return sha256.Sum256(mi.InfoBytes)
+}
@@ -0,0 +1,140 @@
+package metainfo_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode/metainfo"
+)
+
+var (
+	testRoot  = strings.Repeat("r", 32)
+	testLayer = strings.Repeat("\x02", 64)
+	// A file tree with an empty file and a file in a directory
+	testFileTree = "d5:b.txtd0:d6:lengthi0eee4:dir1d5:a.txtd0:d6:lengthi5e11:pieces root32:" + testRoot + "eeee"
+	v2Info       = "d9:file tree" + testFileTree + "12:meta versioni2e4:name4:test12:piece lengthi16384ee"
+	v2File       = "d8:announce9:http://a/4:info" + v2Info + "12:piece layersd32:" + testRoot + "64:" + testLayer + "ee"
+	// The same files in a hybrid torrent, with a padding file for v1
+	hybridInfo = "d9:file tree" + testFileTree +
+		"5:filesld6:lengthi0e4:pathl5:b.txteed6:lengthi5e4:pathl4:dir15:a.txteed4:attr1:p6:lengthi16379e4:pathl4:.pad5:16379eee" +
+		"12:meta versioni2e4:name4:test12:piece lengthi16384e6:pieces20:" + testPieces[:20] + "e"
+	hybridFile = "d4:info" + hybridInfo + "12:piece layersd32:" + testRoot + "64:" + testLayer + "ee"
+)
+
+func TestFileTree(t *testing.T) {
+	mi, err := metainfo.Load(strings.NewReader(v2File))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if hash := mi.InfoHashV2(); hash != sha256.Sum256([]byte(v2Info)) {
+		t.Fatalf("Expected v2 info-hash %x, got %v", sha256.Sum256([]byte(v2Info)), hash)
+	}
+	if layer := mi.PieceLayers[testRoot]; string(layer) != testLayer {
+		t.Fatalf("Expected piece layer %q, got %q", testLayer, layer)
+	}
+	info, err := mi.Info()
+	if err != nil {
+		t.Fatalf("Info returned error: %v", err)
+	}
+	if info.HasV1() || !info.HasV2() || !info.IsMultiFile() || info.TotalLength() != 5 {
+		t.Fatalf("Unexpected v2 info %v", info)
+	}
+	expectedFiles := []metainfo.TreeFile{
+		{Path: []string{"b.txt"}, FileTreeFile: metainfo.FileTreeFile{Length: 0}},
+		{Path: []string{"dir1", "a.txt"}, FileTreeFile: metainfo.FileTreeFile{Length: 5, PiecesRoot: []byte(testRoot)}},
+	}
+	if files := info.FileTree.Files(); !reflect.DeepEqual(files, expectedFiles) {
+		t.Fatalf("Expected files %v, got %v", expectedFiles, files)
+	}
+	expectedEntries := []metainfo.FileEntry{
+		{Length: 0, Path: []string{"b.txt"}},
+		{Length: 5, Path: []string{"dir1", "a.txt"}},
+	}
+	if entries := info.FileEntries(); !reflect.DeepEqual(entries, expectedEntries) {
+		t.Fatalf("Expected file entries %v, got %v", expectedEntries, entries)
+	}
+	// Write it back unchanged, both as a whole and the info dictionary alone
+	buf := bytes.Buffer{}
+	if err := mi.Write(&buf); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if buf.String() != v2File {
+		t.Fatalf("Expected Write to return %q, got %q", v2File, buf.String())
+	}
+	if err := mi.SetInfo(info); err != nil {
+		t.Fatalf("SetInfo returned error: %v", err)
+	}
+	if string(mi.InfoBytes) != v2Info {
+		t.Fatalf("Expected SetInfo to encode %q, got %q", v2Info, mi.InfoBytes)
+	}
+	// A single file at the root
+	single := metainfo.Info{
+		Name:        "a.txt",
+		MetaVersion: 2,
+		FileTree: &metainfo.FileTree{Dir: map[string]*metainfo.FileTree{
+			"a.txt": {File: &metainfo.FileTreeFile{Length: 3}},
+		}},
+	}
+	if single.IsMultiFile() {
+		t.Fatalf("Expected a single file torrent")
+	}
+}
+
+func TestHybrid(t *testing.T) {
+	mi, err := metainfo.Load(strings.NewReader(hybridFile))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if mi.InfoHash() != sha1.Sum([]byte(hybridInfo)) || mi.InfoHashV2() != sha256.Sum256([]byte(hybridInfo)) {
+		t.Fatalf("Unexpected info-hashes %v and %v", mi.InfoHash(), mi.InfoHashV2())
+	}
+	info, err := mi.Info()
+	if err != nil {
+		t.Fatalf("Info returned error: %v", err)
+	}
+	if !info.HasV1() || !info.HasV2() || info.TotalLength() != 5 || info.FileTree.TotalLength() != 5 {
+		t.Fatalf("Unexpected hybrid info %v", info)
+	}
+	if entries := info.FileEntries(); len(entries) != 3 || !entries[2].IsPadding() || entries[1].IsPadding() {
+		t.Fatalf("Expected v1 file entries with a padding file, got %v", entries)
+	}
+	if err := mi.SetInfo(info); err != nil {
+		t.Fatalf("SetInfo returned error: %v", err)
+	}
+	if string(mi.InfoBytes) != hybridInfo {
+		t.Fatalf("Expected SetInfo to encode %q, got %q", hybridInfo, mi.InfoBytes)
+	}
+}
+
+func TestInvalidFileTree(t *testing.T) {
+	for _, invalid := range []string{
+		"d12:meta versioni2e4:name1:xe",
+		"d9:file treed0:d6:lengthi1ee1:xdee12:meta versioni2ee",
+		"d9:file treed1:ad0:d6:lengthi1e11:pieces root3:abceee12:meta versioni2ee",
+		"d9:file treed1:ai1ee12:meta versioni2ee",
+		"d9:file treeli1ee12:meta versioni2ee",
+		"d9:file treed1:ade0:d6:lengthi1eee12:meta versioni2ee",
+	} {
+		mi := metainfo.MetaInfo{InfoBytes: []byte(invalid)}
+		if info, err := mi.Info(); err == nil {
+			t.Fatalf("Expected Info of %q to fail, got %v", invalid, info)
+		}
+	}
+}
+
+func TestDeepFileTree(t *testing.T) {
+	// A file nested in 100 directories
+	tree := strings.Repeat("d1:a", 100) + "d0:d6:lengthi1eee" + strings.Repeat("e", 100)
+	ft := new(metainfo.FileTree)
+	if err := ft.UnmarshalBencode([]byte(tree)); err != nil {
+		t.Fatalf("UnmarshalBencode returned error: %v", err)
+	}
+	files := ft.Files()
+	if len(files) != 1 || len(files[0].Path) != 100 || files[0].Length != 1 {
+		t.Fatalf("Unexpected files %v", files)
+	}
+}
@@ -0,0 +1,280 @@
+// Package metainfo reads and writes BitTorrent metainfo (.torrent) files.
+package metainfo
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+var (
+	// Error for a metainfo file without an info dictionary
+	ErrNoInfo = errors.New("metainfo: missing info dictionary")
+	// Error for an info value that is not a dictionary
+	ErrInvalidInfo = errors.New("metainfo: info must be a dictionary")
+	// Error for a pieces string that is not a multiple of the hash size
+	ErrInvalidPieces = errors.New("metainfo: pieces length must be a multiple of 20")
+)
+
+// The size of a SHA-1 hash, used for the info-hash and the piece hashes
+const HashSize = sha1.Size
+
+// A SHA-1 hash, such as the info-hash of a torrent
+type Hash [HashSize]byte
+
+// Returns the hash as a lowercase hex string
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// The content of a .torrent file.
+//
+// The info dictionary is kept as its original bytes, so the info-hash
+// stays the same even if the dictionary contains unknown keys or isn't
+// in its canonical form. Use Info and SetInfo to access it.
+//
+// Top-level keys without a field, such as "url-list" (BEP 19), are kept
+// in Extra so that Write returns them unchanged.
+type MetaInfo struct {
+	Announce     string             `bencode:"announce,omitempty"`
+	AnnounceList [][]string         `bencode:"announce-list,omitempty"`
+	Comment      string             `bencode:"comment,omitempty"`
+	CreatedBy    string             `bencode:"created by,omitempty"`
+	CreationDate int64              `bencode:"creation date,omitempty"`
+	Encoding     string             `bencode:"encoding,omitempty"`
+	InfoBytes    bencode.RawMessage `bencode:"info"`
+	// The piece hashes of the files of a v2 torrent by pieces root (BEP 52)
+	PieceLayers map[string][]byte `bencode:"piece layers,omitempty"`
+	// The original bytes of the top-level keys without a field
+	Extra map[string]bencode.RawMessage `bencode:"-"`
+}
+
+// The top-level keys of the fields of MetaInfo
+var metaInfoKeys = func() map[string]bool {
+	keys := map[string]bool{}
+	t := reflect.TypeOf(MetaInfo{})
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("bencode"); tag != "-" {
+			name, _, _ := strings.Cut(tag, ",")
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+// The info dictionary of a torrent.
+//
+// Single-file v1 torrents have a Length and use Name as the file name,
+// multi-file v1 torrents have Files and use Name as the directory name.
+// v2 torrents (BEP 52) have a MetaVersion of 2 and a FileTree instead,
+// hybrid torrents have both.
+type Info struct {
+	Name        string      `bencode:"name"`
+	PieceLength int64       `bencode:"piece length"`
+	Pieces      []byte      `bencode:"pieces,omitempty"`
+	Private     int64       `bencode:"private,omitempty"`
+	Length      int64       `bencode:"length,omitempty"`
+	Files       []FileEntry `bencode:"files,omitempty"`
+	MetaVersion int64       `bencode:"meta version,omitempty"`
+	FileTree    *FileTree   `bencode:"file tree,omitempty"`
+}
+
+// A file of a multi-file v1 torrent
+type FileEntry struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`
+	// The attributes of the file, such as "p" for padding files (BEP 47)
+	Attr string `bencode:"attr,omitempty"`
+}
+
+// Reads a metainfo file from a reader
+func Load(r io.Reader, opts ...bencode.DecoderOption) (*MetaInfo, error) {
+	// The options apply when reading the file, which is then known to be valid
+	raw, err := bencode.NewParserFromReader(r, opts...).AsRaw()
+	if err != nil {
+		return nil, err
+	}
+	mi := new(MetaInfo)
+	if err := bencode.Unmarshal(raw, mi); err != nil {
+		return nil, err
+	}
+	var all map[string]bencode.RawMessage
+	if err := bencode.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	for key, value := range all {
+		if !metaInfoKeys[key] {
+			if mi.Extra == nil {
+				mi.Extra = map[string]bencode.RawMessage{}
+			}
+			mi.Extra[key] = value
+		}
+	}
+	if len(mi.InfoBytes) == 0 {
+		return nil, ErrNoInfo
+	}
+	if mi.InfoBytes[0] != 'd' {
+		return nil, ErrInvalidInfo
+	}
+	return mi, nil
+}
+
+// Reads a metainfo file from a given path. See Load for the options.
+func LoadFromFile(path string, opts ...bencode.DecoderOption) (*MetaInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f, opts...)
+}
+
+// Writes the metainfo file to a writer, including the keys in Extra.
+// The fields take precedence over Extra keys with the same name.
+func (mi *MetaInfo) Write(w io.Writer) error {
+	if len(mi.InfoBytes) == 0 {
+		return ErrNoInfo
+	}
+	if len(mi.Extra) == 0 {
+		return bencode.NewEncoder(w).Encode(mi)
+	}
+	raw, err := bencode.Marshal(mi)
+	if err != nil {
+		return err
+	}
+	var all map[string]bencode.RawMessage
+	if err := bencode.Unmarshal(raw, &all); err != nil {
+		return err
+	}
+	for key, value := range mi.Extra {
+		if _, ok := all[key]; !ok {
+			all[key] = value
+		}
+	}
+	return bencode.NewEncoder(w).Encode(all)
+}
+
+// Returns the SHA-1 hash of the original bytes of the info dictionary
+func (mi *MetaInfo) InfoHash() Hash {
+	return sha1.Sum(mi.InfoBytes)
+}
+
+// Decodes the info dictionary
+func (mi *MetaInfo) Info() (*Info, error) {
+	if len(mi.InfoBytes) == 0 {
+		return nil, ErrNoInfo
+	}
+	info := new(Info)
+	if err := bencode.Unmarshal(mi.InfoBytes, info); err != nil {
+		return nil, err
+	}
+	if len(info.Pieces)%HashSize != 0 {
+		return nil, ErrInvalidPieces
+	}
+	if info.MetaVersion == 2 && info.FileTree == nil {
+		return nil, ErrInvalidFileTree
+	}
+	return info, nil
+}
+
+// Encodes the info dictionary, which changes the info-hash
+func (mi *MetaInfo) SetInfo(info *Info) error {
+	raw, err := bencode.Marshal(info)
+	if err != nil {
+		return err
+	}
+	mi.InfoBytes = raw
+	return nil
+}
+
+// Returns the tiers of trackers of the torrent.
+// Falls back to Announce if there is no announce-list, as per BEP 12.
+func (mi *MetaInfo) AnnounceTiers() [][]string {
+	if len(mi.AnnounceList) > 0 {
+		return mi.AnnounceList
+	}
+	if mi.Announce != "" {
+		return [][]string{{mi.Announce}}
+	}
+	return nil
+}
+
+// Returns true if the torrent has v1 pieces, including hybrid torrents
+func (info *Info) HasV1() bool {
+	return info.Pieces != nil
+}
+
+// Returns true if the torrent has a v2 file tree, including hybrid torrents
+func (info *Info) HasV2() bool {
+	return info.MetaVersion == 2 && info.FileTree != nil
+}
+
+// Returns true for a multi-file torrent
+func (info *Info) IsMultiFile() bool {
+	if !info.HasV1() && info.HasV2() {
+		files := info.FileTree.Files()
+		return len(files) != 1 || len(files[0].Path) != 1
+	}
+	return info.Files != nil
+}
+
+// Returns the files of the torrent.
+// A single-file torrent has one entry with Name as its path. The files
+// of a v2-only torrent are taken from its file tree, sorted by path.
+func (info *Info) FileEntries() []FileEntry {
+	if !info.HasV1() && info.HasV2() {
+		files := []FileEntry{}
+		for _, f := range info.FileTree.Files() {
+			files = append(files, FileEntry{Length: f.Length, Path: f.Path})
+		}
+		return files
+	}
+	if info.IsMultiFile() {
+		return info.Files
+	}
+	return []FileEntry{{Length: info.Length, Path: []string{info.Name}}}
+}
+
+// Returns the total length of the files of the torrent, excluding padding
+func (info *Info) TotalLength() int64 {
+	if !info.HasV1() && info.HasV2() {
+		return info.FileTree.TotalLength()
+	}
+	if !info.IsMultiFile() {
+		return info.Length
+	}
+	total := int64(0)
+	for _, f := range info.Files {
+		if !f.IsPadding() {
+			total += f.Length
+		}
+	}
+	return total
+}
+
+// Returns true for the padding files of hybrid torrents (BEP 47)
+func (f *FileEntry) IsPadding() bool {
+	return strings.Contains(f.Attr, "p")
+}
+
+// Returns the number of v1 pieces of the torrent
+func (info *Info) NumPieces() int {
+	return len(info.Pieces) / HashSize
+}
+
+// Returns the SHA-1 hash of the piece at a given index, or false if the
+// index is not in [0, NumPieces())
+func (info *Info) PieceHash(index int) (Hash, bool) {
+	var h Hash
+	if index < 0 || index >= info.NumPieces() {
+		return h, false
+	}
+	copy(h[:], info.Pieces[index*HashSize:(index+1)*HashSize])
+	return h, true
+}
@@ -0,0 +1,198 @@
+package metainfo_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+	"github.com/stefanovazzocell/bencode/metainfo"
+)
+
+var (
+	testPieces = strings.Repeat("\x01", 20) + strings.Repeat("\xff", 20)
+	// Info dictionaries, including one with an unknown key and unsorted keys
+	singleFileInfo = "d6:lengthi170917888e4:name30:debian-8.8.0-arm64-netinst.iso12:piece lengthi262144e6:pieces40:" + testPieces + "e"
+	multiFileInfo  = "d5:filesld6:lengthi3e4:pathl1:a5:b.txteed6:lengthi4e4:pathl5:c.txteee4:name3:dir12:piece lengthi16384e6:pieces20:" + testPieces[:20] + "7:privatei1ee"
+	unsortedInfo   = "d4:name1:x6:pieces0:12:piece lengthi1e6:sourcei1e6:lengthi0ee"
+	singleFile     = "d8:announce38:udp://tracker.publicbt.com:80/announce7:comment33:Debian CD from cdimage.debian.org4:info" + singleFileInfo + "e"
+	multiFile      = "d13:announce-listll9:http://a/el9:http://b/ee10:created by4:test13:creation datei1700000000e4:info" + multiFileInfo + "e"
+	unsortedFile   = "d4:info" + unsortedInfo + "e"
+)
+
+func TestLoad(t *testing.T) {
+	for torrent, rawInfo := range map[string]string{
+		singleFile:   singleFileInfo,
+		multiFile:    multiFileInfo,
+		unsortedFile: unsortedInfo,
+	} {
+		mi, err := metainfo.Load(strings.NewReader(torrent))
+		if err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		// The info-hash uses the original bytes
+		if string(mi.InfoBytes) != rawInfo {
+			t.Fatalf("Expected info bytes %q, got %q", rawInfo, mi.InfoBytes)
+		}
+		if hash := mi.InfoHash(); hash != sha1.Sum([]byte(rawInfo)) {
+			t.Fatalf("Expected info-hash %x, got %v", sha1.Sum([]byte(rawInfo)), hash)
+		}
+		// Write it back unchanged
+		buf := bytes.Buffer{}
+		if err := mi.Write(&buf); err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+		if buf.String() != torrent {
+			t.Fatalf("Expected Write to return %q, got %q", torrent, buf.String())
+		}
+	}
+	// Invalid files
+	for _, invalid := range []string{"", "de", "d8:announce1:xe", "d4:infoi1ee", "l4:infoe"} {
+		if mi, err := metainfo.Load(strings.NewReader(invalid)); err == nil {
+			t.Fatalf("Expected Load of %q to fail, got %v", invalid, mi)
+		}
+	}
+}
+
+func TestExtraKeys(t *testing.T) {
+	torrent := "d4:info" + singleFileInfo + "8:url-listl14:http://mirror/ee"
+	mi, err := metainfo.Load(strings.NewReader(torrent))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	expected := map[string]bencode.RawMessage{"url-list": bencode.RawMessage("l14:http://mirror/e")}
+	if !reflect.DeepEqual(mi.Extra, expected) {
+		t.Fatalf("Expected extra keys %v, got %v", expected, mi.Extra)
+	}
+	// Write keeps the extra keys, sorted along with the fields
+	mi.Comment = "test"
+	buf := bytes.Buffer{}
+	if err := mi.Write(&buf); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	expectedFile := "d7:comment4:test4:info" + singleFileInfo + "8:url-listl14:http://mirror/ee"
+	if buf.String() != expectedFile {
+		t.Fatalf("Expected Write to return %q, got %q", expectedFile, buf.String())
+	}
+}
+
+func TestLargeFile(t *testing.T) {
+	// A length over 2^32, which doesn't fit in an int on 32-bit platforms
+	info := "d6:lengthi8589934592e4:name3:big12:piece lengthi4194304e6:pieces40:" + testPieces + "e"
+	mi, err := metainfo.Load(strings.NewReader("d4:info" + info + "e"))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if string(mi.InfoBytes) != info {
+		t.Fatalf("Expected info bytes %q, got %q", info, mi.InfoBytes)
+	}
+	decoded, err := mi.Info()
+	if err != nil {
+		t.Fatalf("Info returned error: %v", err)
+	}
+	if decoded.TotalLength() != 1<<33 {
+		t.Fatalf("Expected a total length of %d, got %d", int64(1)<<33, decoded.TotalLength())
+	}
+}
+
+func TestLoadFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.torrent")
+	if err := os.WriteFile(path, []byte(multiFile), 0600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	mi, err := metainfo.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile returned error: %v", err)
+	}
+	if mi.CreatedBy != "test" || mi.CreationDate != 1700000000 {
+		t.Fatalf("Unexpected metainfo %v", mi)
+	}
+	// The options are used to read the file
+	if _, err := metainfo.LoadFromFile(path, bencode.WithMaxTotalBytes(10)); err == nil {
+		t.Fatalf("Expected LoadFromFile with a byte limit to fail")
+	}
+	if _, err := metainfo.LoadFromFile(filepath.Join(t.TempDir(), "missing.torrent")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	// Single-file
+	mi, err := metainfo.Load(strings.NewReader(singleFile))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	info, err := mi.Info()
+	if err != nil {
+		t.Fatalf("Info returned error: %v", err)
+	}
+	if info.IsMultiFile() || info.TotalLength() != 170917888 || info.NumPieces() != 2 {
+		t.Fatalf("Unexpected single-file info %v", info)
+	}
+	expectedFiles := []metainfo.FileEntry{{Length: 170917888, Path: []string{"debian-8.8.0-arm64-netinst.iso"}}}
+	if files := info.FileEntries(); !reflect.DeepEqual(files, expectedFiles) {
+		t.Fatalf("Expected files %v, got %v", expectedFiles, files)
+	}
+	if hash, ok := info.PieceHash(1); !ok || string(hash[:]) != testPieces[20:] {
+		t.Fatalf("Unexpected piece hash %v", hash)
+	}
+	for _, index := range []int{-1, 2} {
+		if hash, ok := info.PieceHash(index); ok {
+			t.Fatalf("Expected no piece hash at index %d, got %v", index, hash)
+		}
+	}
+	if tiers := mi.AnnounceTiers(); !reflect.DeepEqual(tiers, [][]string{{mi.Announce}}) {
+		t.Fatalf("Expected announce to be the only tier, got %v", tiers)
+	}
+	// Multi-file
+	mi, err = metainfo.Load(strings.NewReader(multiFile))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	info, err = mi.Info()
+	if err != nil {
+		t.Fatalf("Info returned error: %v", err)
+	}
+	if !info.IsMultiFile() || info.TotalLength() != 7 || info.NumPieces() != 1 || info.Private != 1 {
+		t.Fatalf("Unexpected multi-file info %v", info)
+	}
+	expectedFiles = []metainfo.FileEntry{
+		{Length: 3, Path: []string{"a", "b.txt"}},
+		{Length: 4, Path: []string{"c.txt"}},
+	}
+	if files := info.FileEntries(); !reflect.DeepEqual(files, expectedFiles) {
+		t.Fatalf("Expected files %v, got %v", expectedFiles, files)
+	}
+	if tiers := mi.AnnounceTiers(); !reflect.DeepEqual(tiers, [][]string{{"http://a/"}, {"http://b/"}}) {
+		t.Fatalf("Unexpected tiers %v", tiers)
+	}
+	// SetInfo re-encodes the dictionary canonically
+	if err := mi.SetInfo(info); err != nil {
+		t.Fatalf("SetInfo returned error: %v", err)
+	}
+	if string(mi.InfoBytes) != multiFileInfo {
+		t.Fatalf("Expected SetInfo to encode %q, got %q", multiFileInfo, mi.InfoBytes)
+	}
+	// Invalid info dictionaries
+	for _, invalid := range []string{"d6:pieces3:abce", "d4:namei1ee"} {
+		mi := metainfo.MetaInfo{InfoBytes: []byte(invalid)}
+		if info, err := mi.Info(); err == nil {
+			t.Fatalf("Expected Info of %q to fail, got %v", invalid, info)
+		}
+	}
+	empty := metainfo.MetaInfo{}
+	if _, err := empty.Info(); err != metainfo.ErrNoInfo {
+		t.Fatalf("Expected ErrNoInfo, got %v", err)
+	}
+	if err := empty.Write(&bytes.Buffer{}); err != metainfo.ErrNoInfo {
+		t.Fatalf("Expected ErrNoInfo from Write, got %v", err)
+	}
+	if tiers := empty.AnnounceTiers(); tiers != nil {
+		t.Fatalf("Expected no tiers, got %v", tiers)
+	}
+}
@@ -0,0 +1,154 @@
+package bencode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Error for a path that selects no value
+	ErrPathNotFound = errors.New("path not found")
+	// Error for a malformed path expression such as "a[x]"
+	ErrInvalidPath = errors.New("invalid path")
+)
+
+// Splits a path expression such as "info.name" or "announce-list[0][0]"
+// into the dictionary keys and list indices used by Get and Find.
+// An empty path selects the whole value.
+//
+// List indices are written in decimal without a sign or leading zeros.
+// Keys that are empty or contain "." or "[" can't be written in a path
+// expression; use Get with the keys instead.
+func ParsePath(path string) ([]string, error) {
+	keys := []string{}
+	for rest := path; rest != ""; {
+		var key string
+		if index, ok := strings.CutPrefix(rest, "["); ok {
+			key, rest, ok = strings.Cut(index, "]")
+			if _, isIndex := ParseIndex(key); !ok || !isIndex {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+			}
+			// An index is followed by the end, a dot or another index
+			if rest != "" && rest[0] != '.' && rest[0] != '[' {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], rest[end:]
+		}
+		// Keys are separated by dots, and a dot must be followed by a key
+		var dot bool
+		if rest, dot = strings.CutPrefix(rest, "."); key == "" || dot && rest == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Returns the list index selected by an element of a path, or false if
+// it is not a decimal number without a sign or leading zeros
+func ParseIndex(key string) (int, bool) {
+	index, err := strconv.Atoi(key)
+	if err != nil || index < 0 || strconv.Itoa(index) != key {
+		return 0, false
+	}
+	return index, true
+}
+
+// Returns the raw bencode of the value at a path, such as
+// Get(data, "announce-list", "0", "0"). Each element of the path is a
+// dictionary key, or an index in a list written as in ParsePath.
+//
+// The values that are not on the path are skipped without being allocated.
+// The returned value is a slice of data.
+func Get(data []byte, path ...string) (RawMessage, error) {
+	d := NewParserFromBytes(data, WithZeroCopy())
+	if err := d.Find(path...); err != nil {
+		return nil, err
+	}
+	start := d.InputOffset()
+	if err := d.Skip(); err != nil {
+		return nil, err
+	}
+	end := d.InputOffset()
+	return RawMessage(data[start:end:end]), nil
+}
+
+// Returns the raw bencode of the value at a path expression such as
+// "announce-list[0][0]". See ParsePath and Get.
+func GetPath(data []byte, path string) (RawMessage, error) {
+	keys, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return Get(data, keys...)
+}
+
+// Reads the next value up to the value at a path, skipping the values
+// that are not on the path. See Get for the format of the path.
+//
+// If successful, the next read of the decoder returns the value at the path,
+// for example with AsString or Decode, and Token can be used to read the rest
+// of its lists and dictionaries. Otherwise fails with ErrPathNotFound.
+func (d *Decoder) Find(path ...string) error {
+	for _, key := range path {
+		if err := d.findKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reads the start of a list or dictionary up to the value of a key or index
+func (d *Decoder) findKey(key string) error {
+	token, err := d.Token()
+	if err != nil {
+		return err
+	}
+	switch token.Type {
+	case DictStart:
+		for {
+			if next, err := d.Next(); err != nil {
+				return d.eofError("dictionary key or end", err)
+			} else if next == End {
+				break
+			}
+			// Keys are read with Token to track the position in the dictionary
+			k, err := d.Token()
+			if err != nil {
+				return err
+			}
+			if k.String == key {
+				return nil
+			}
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		}
+	case ListStart:
+		index, ok := ParseIndex(key)
+		if !ok {
+			break
+		}
+		for i := 0; ; i++ {
+			if next, err := d.Next(); err != nil {
+				return d.eofError("list item or end", err)
+			} else if next == End {
+				break
+			}
+			if i == index {
+				return nil
+			}
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrPathNotFound, key)
+}
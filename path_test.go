@@ -0,0 +1,127 @@
+package bencode_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+const pathTestDocument = "d8:announce9:http://a/13:announce-listll9:http://a/el9:http://b/9:http://c/ee4:infod6:lengthi1e4:name5:a.isoee"
+
+func TestParsePath(t *testing.T) {
+	testCases := map[string][]string{
+		"":                    {},
+		"info":                {"info"},
+		"info.name":           {"info", "name"},
+		"info.piece length":   {"info", "piece length"},
+		"announce-list[1][0]": {"announce-list", "1", "0"},
+		"[0].a":               {"0", "a"},
+		"a[0].b[2]":           {"a", "0", "b", "2"},
+	}
+	for path, expected := range testCases {
+		keys, err := bencode.ParsePath(path)
+		if err != nil {
+			t.Fatalf("ParsePath of %q returned error: %v", path, err)
+		}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Fatalf("Expected %q for %q, got %q", expected, path, keys)
+		}
+	}
+	for _, invalid := range []string{".", "a.", "a..b", ".a", "a[", "a[]", "a[x]", "a[0",
+		"a[0]b", "a[0]]", "a[+1]", "a[-1]", "a[01]", "a[ 1]"} {
+		if keys, err := bencode.ParsePath(invalid); !errors.Is(err, bencode.ErrInvalidPath) {
+			t.Fatalf("Expected ErrInvalidPath for %q, got (%q, %v)", invalid, keys, err)
+		}
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	for key, expected := range map[string]int{"0": 0, "7": 7, "120": 120} {
+		if index, ok := bencode.ParseIndex(key); !ok || index != expected {
+			t.Fatalf("Expected index %d for %q, got (%d, %v)", expected, key, index, ok)
+		}
+	}
+	for _, invalid := range []string{"", "x", "01", "00", "+1", "-1", "-0", " 1", "1.0", "99999999999999999999"} {
+		if index, ok := bencode.ParseIndex(invalid); ok {
+			t.Fatalf("Expected %q not to be an index, got %d", invalid, index)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	testCases := map[string]string{
+		"":                    pathTestDocument,
+		"announce":            "9:http://a/",
+		"announce-list[1]":    "l9:http://b/9:http://c/e",
+		"announce-list[1][1]": "9:http://c/",
+		"info":                "d6:lengthi1e4:name5:a.isoe",
+		"info.length":         "i1e",
+	}
+	for path, expected := range testCases {
+		raw, err := bencode.GetPath([]byte(pathTestDocument), path)
+		if err != nil {
+			t.Fatalf("GetPath of %q returned error: %v", path, err)
+		}
+		if string(raw) != expected {
+			t.Fatalf("Expected %q for %q, got %q", expected, path, raw)
+		}
+	}
+	raw, err := bencode.Get([]byte(pathTestDocument), "info", "name")
+	if err != nil || string(raw) != "5:a.iso" {
+		t.Fatalf("Expected %q, got (%q, %v)", "5:a.iso", raw, err)
+	}
+	for _, missing := range []string{"comment", "info.name.x", "announce-list[2]", "announce-list.x", "info[0]"} {
+		if raw, err := bencode.GetPath([]byte(pathTestDocument), missing); !errors.Is(err, bencode.ErrPathNotFound) {
+			t.Fatalf("Expected ErrPathNotFound for %q, got (%q, %v)", missing, raw, err)
+		}
+	}
+	// List indices must be canonical
+	for _, index := range []string{"01", "+1", "-1", " 1"} {
+		if raw, err := bencode.Get([]byte(pathTestDocument), "announce-list", index); !errors.Is(err, bencode.ErrPathNotFound) {
+			t.Fatalf("Expected ErrPathNotFound for index %q, got (%q, %v)", index, raw, err)
+		}
+	}
+	if _, err := bencode.GetPath([]byte(pathTestDocument), "a["); !errors.Is(err, bencode.ErrInvalidPath) {
+		t.Fatalf("Expected ErrInvalidPath, got %v", err)
+	}
+	// Invalid values on the path are reported, but not the ones after the target
+	for _, invalid := range []string{"d4:infoi1x", "d4:info", "d1:a1:b"} {
+		if raw, err := bencode.Get([]byte(invalid), "info"); err == nil || errors.Is(err, bencode.ErrPathNotFound) {
+			t.Fatalf("Expected a syntax error for %q, got (%q, %v)", invalid, raw, err)
+		}
+	}
+	// Values skipped on the way can be integers of any size
+	if raw, err := bencode.Get([]byte("d1:ai99999999999999999999999e1:b3:xyze"), "b"); err != nil || string(raw) != "3:xyz" {
+		t.Fatalf("Expected %q, got (%q, %v)", "3:xyz", raw, err)
+	}
+	if raw, err := bencode.Get([]byte("d4:infoi1e1:x"), "info"); err != nil || string(raw) != "i1e" {
+		t.Fatalf("Expected %q, got (%q, %v)", "i1e", raw, err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	d := bencode.NewParserFromString(pathTestDocument)
+	if err := d.Find("info", "name"); err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if name, err := d.AsString(); err != nil || name != "a.iso" {
+		t.Fatalf("Expected %q, got (%q, %v)", "a.iso", name, err)
+	}
+	// The rest of the document can still be read with tokens
+	for _, expected := range []bencode.TokenType{bencode.End, bencode.End} {
+		if token, err := d.Token(); err != nil || token.Type != expected {
+			t.Fatalf("Expected %v, got (%v, %v)", expected, token, err)
+		}
+	}
+	// Values can be decoded into typed values
+	var trackers []string
+	d = bencode.NewParserFromString(pathTestDocument)
+	if err := d.Find("announce-list", "1"); err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if err := d.Decode(&trackers); err != nil || !reflect.DeepEqual(trackers, []string{"http://b/", "http://c/"}) {
+		t.Fatalf("Unexpected trackers (%q, %v)", trackers, err)
+	}
+}
@@ -0,0 +1,181 @@
+package bencode
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+var (
+	// Error for a raw message that is not exactly one bencode value
+	ErrInvalidRawMessage = errors.New("invalid bencode: raw message must contain exactly one value")
+)
+
+// A type that can encode itself as bencode
+type Marshaler interface {
+	MarshalBencode() ([]byte, error)
+}
+
+// A type that can decode a bencode representation of itself.
+// The data given to UnmarshalBencode must be copied if it's retained.
+type Unmarshaler interface {
+	UnmarshalBencode(data []byte) error
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+// The exact original bytes of a bencode value.
+//
+// It can be used to delay the decoding of a value or to keep its exact
+// encoding, such as the info dictionary of a torrent to compute its
+// info-hash. The encoder writes it back unchanged.
+type RawMessage []byte
+
+// Returns m as the bencode encoding of m
+func (m RawMessage) MarshalBencode() ([]byte, error) {
+	return m, nil
+}
+
+// Sets *m to a copy of data
+func (m *RawMessage) UnmarshalBencode(data []byte) error {
+	if m == nil {
+		return errors.New("bencode.RawMessage: UnmarshalBencode on nil pointer")
+	}
+	*m = append((*m)[0:0], data...)
+	return nil
+}
+
+// Returns an error if raw is not exactly one valid bencode value
+func validateRaw(raw []byte) error {
+	d := NewParserFromString(string(raw), WithMaxStringLength(len(raw)))
+	t, err := d.readByte()
+	if err != nil {
+		return ErrInvalidRawMessage
+	}
+	if err := d.skipValue(t); err != nil {
+		return err
+	}
+	if _, err := d.readByte(); err != io.EOF {
+		return ErrInvalidRawMessage
+	}
+	return nil
+}
+
+// Writes the output of a Marshaler to the encoder output
+func (e *Encoder) writeMarshaler(m Marshaler) error {
+	raw, err := m.MarshalBencode()
+	if err != nil {
+		return err
+	}
+	if err := validateRaw(raw); err != nil {
+		return err
+	}
+	_, err = e.output().Write(raw)
+	return err
+}
+
+// Appends the raw bytes of a string to dst, returning the string as well
+func (d *Decoder) appendRawString(dst []byte) ([]byte, string, error) {
+	number, start, err := d.readNumber(':')
+	if err != nil {
+		return dst, "", err
+	}
+	length, err := d.parseLength(number, start, true)
+	if err != nil {
+		return dst, "", err
+	}
+	s, err := d.readStringData(length)
+	if err != nil {
+		return dst, "", err
+	}
+	dst = append(dst, number...)
+	dst = append(dst, ':')
+	return append(dst, s...), s, nil
+}
+
+// Appends the exact original bytes of a value to dst.
+// Expects a type byte to be provided.
+func (d *Decoder) appendRaw(dst []byte, t byte) ([]byte, error) {
+	switch t {
+	case 'i':
+		number, err := d.readIntAnySize()
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, 'i')
+		dst = append(dst, number...)
+		return append(dst, 'e'), nil
+	case 'l':
+		if err := d.enter(t); err != nil {
+			return dst, err
+		}
+		defer d.leave()
+		dst = append(dst, 'l')
+		for {
+			t, err := d.readByteIn("list item or end")
+			if err != nil {
+				return dst, err
+			}
+			if t == 'e' {
+				return append(dst, 'e'), nil
+			}
+			if dst, err = d.appendRaw(dst, t); err != nil {
+				return dst, err
+			}
+		}
+	case 'd':
+		if err := d.enter(t); err != nil {
+			return dst, err
+		}
+		defer d.leave()
+		dst = append(dst, 'd')
+		prev := ""
+		for i := 0; ; i++ {
+			// Check if end
+			t, err := d.readByteIn("dictionary key or end")
+			if err != nil {
+				return dst, err
+			}
+			if t == 'e' {
+				return append(dst, 'e'), nil
+			}
+			if t == 'i' || t == 'l' || t == 'd' {
+				return dst, syntaxError(d.offset()-1, "dictionary key", t, ErrInvalidType)
+			}
+			d.undoReadByte()
+			// Read key
+			start := d.offset()
+			var key string
+			if dst, key, err = d.appendRawString(dst); err != nil {
+				return dst, err
+			}
+			if err := d.checkKey(prev, key, i > 0, start); err != nil {
+				return dst, err
+			}
+			prev = key
+			// Read value
+			if t, err = d.readByteIn("dictionary value"); err != nil {
+				return dst, err
+			}
+			if dst, err = d.appendRaw(dst, t); err != nil {
+				return dst, err
+			}
+		}
+	}
+	d.undoReadByte()
+	dst, _, err := d.appendRawString(dst)
+	return dst, err
+}
+
+// Reads a single value from the decoder and returns its exact original bytes.
+func (d *Decoder) AsRaw() (RawMessage, error) {
+	t, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := d.appendRaw(nil, t)
+	return raw, d.done(err)
+}
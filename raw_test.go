@@ -0,0 +1,165 @@
+package bencode_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+type testRawTorrent struct {
+	Announce string             `bencode:"announce"`
+	Info     bencode.RawMessage `bencode:"info"`
+}
+
+// A type with custom encoding, stored as a string of its decimal value
+type testDecimal int
+
+func (d testDecimal) MarshalBencode() ([]byte, error) {
+	s := fmt.Sprint(int(d))
+	return []byte(fmt.Sprintf("%d:%s", len(s), s)), nil
+}
+
+func (d *testDecimal) UnmarshalBencode(data []byte) error {
+	s, err := bencode.NewParserFromString(string(data)).AsString()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Sscan(s, (*int)(d))
+	return err
+}
+
+var (
+	rawTestCases = []string{
+		"i0e",
+		"i-0e",
+		"i03e",
+		"i+5e",
+		"0:",
+		"05:hello",
+		"le",
+		"li1e05:helloe",
+		"d1:bi1e1:ai2ee",
+		"d1:ai1e1:ai2ee",
+		debianTorrentEncoded,
+		complexMapTranslated,
+	}
+)
+
+func TestRawMessage(t *testing.T) {
+	for _, test := range rawTestCases {
+		// Raw bytes are kept verbatim, even when not canonical
+		for _, decoder := range []*bencode.Decoder{
+			bencode.NewParserFromString(test + "i42e"),
+			bencode.NewParserFromReader(strings.NewReader(test + "i42e")),
+			bencode.NewParserFromReader(newChaosReader(test + "i42e")),
+		} {
+			raw, err := decoder.AsRaw()
+			if err != nil {
+				t.Fatalf("AsRaw of %q returned error: %v", test, err)
+			}
+			if string(raw) != test {
+				t.Fatalf("Expected raw %q, but got %q", test, raw)
+			}
+			if n, err := decoder.AsInt(); err != nil || n != 42 {
+				t.Fatalf("Expected 42 after raw %q, but got (%d, %v)", test, n, err)
+			}
+		}
+		// Raw bytes are written back unchanged
+		encoded, err := bencode.Marshal(bencode.RawMessage(test))
+		if err != nil {
+			t.Fatalf("Marshal of raw %q returned error: %v", test, err)
+		}
+		if string(encoded) != test {
+			t.Fatalf("Expected %q from Marshal, but got %q", test, encoded)
+		}
+		encoder, err := bencode.NewEncoderFromInterface([]interface{}{bencode.RawMessage(test)})
+		if err != nil {
+			t.Fatalf("NewEncoderFromInterface of raw %q returned error: %v", test, err)
+		}
+		if expected := "l" + test + "e"; encoder.String() != expected {
+			t.Fatalf("Expected %q from NewEncoderFromInterface, but got %q", expected, encoder.String())
+		}
+	}
+	// Invalid raw values are rejected by both the decoder and the encoder
+	for _, invalid := range invalidParserInputs {
+		if raw, err := bencode.NewParserFromString(invalid).AsRaw(); err == nil {
+			t.Fatalf("Expected AsRaw of invalid %q to fail.\nInstead got %q", invalid, raw)
+		}
+		if encoded, err := bencode.Marshal(bencode.RawMessage(invalid)); err == nil {
+			t.Fatalf("Expected Marshal of invalid raw %q to fail.\nInstead got %q", invalid, encoded)
+		}
+	}
+	if encoded, err := bencode.Marshal(bencode.RawMessage("i1ei2e")); err != bencode.ErrInvalidRawMessage {
+		t.Fatalf("Expected ErrInvalidRawMessage for trailing data, got (%q, %v)", encoded, err)
+	}
+	// Strict mode and limits still apply
+	for test, expected := range strictInvalidTestCases {
+		if raw, err := bencode.NewParserFromString(test, bencode.WithStrict()).AsRaw(); !errors.Is(err, expected) {
+			t.Fatalf("Expected AsRaw of %q to fail with %v, got (%q, %v)", test, expected, raw, err)
+		}
+	}
+	if raw, err := bencode.NewParserFromString("llleee", bencode.WithMaxDepth(2)).AsRaw(); !errors.Is(err, bencode.ErrMaxDepth) {
+		t.Fatalf("Expected AsRaw to fail with ErrMaxDepth, got (%q, %v)", raw, err)
+	}
+}
+
+func TestRawMessageStruct(t *testing.T) {
+	const info = "d6:lengthi170917888e12:piece lengthi262144e4:name30:debian-8.8.0-arm64-netinst.isoe"
+	var torrent testRawTorrent
+	if err := bencode.Unmarshal([]byte(debianTorrentEncoded), &torrent); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if string(torrent.Info) != info {
+		t.Fatalf("Expected raw info %q, but got %q", info, torrent.Info)
+	}
+	// Decoding the raw message later
+	var parsedInfo testInfo
+	if err := bencode.Unmarshal(torrent.Info, &parsedInfo); err != nil {
+		t.Fatalf("Unmarshal of raw info returned error: %v", err)
+	}
+	if !reflect.DeepEqual(&parsedInfo, debianTorrentStruct.Info) {
+		t.Fatalf("Expected %v, but got %v", debianTorrentStruct.Info, parsedInfo)
+	}
+	// Encoding keeps the raw message unchanged
+	encoded, err := bencode.Marshal(&torrent)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if expected := "d8:announce38:udp://tracker.publicbt.com:80/announce4:info" + info + "e"; string(encoded) != expected {
+		t.Fatalf("Expected %q, but got %q", expected, encoded)
+	}
+}
+
+func TestMarshalerInterfaces(t *testing.T) {
+	values := []testDecimal{-5, 0, 123}
+	encoded, err := bencode.Marshal(values)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if expected := "l2:-51:03:123e"; string(encoded) != expected {
+		t.Fatalf("Expected %q, but got %q", expected, encoded)
+	}
+	var decoded []testDecimal
+	if err := bencode.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, values) {
+		t.Fatalf("Expected %v, but got %v", values, decoded)
+	}
+	if err := bencode.Unmarshal([]byte("li1ee"), &decoded); err == nil {
+		t.Fatalf("Expected UnmarshalBencode error to be returned")
+	}
+	// The streaming encoder writes marshalers too
+	buf := bytes.Buffer{}
+	if err := bencode.NewEncoder(&buf).Encode(map[string]testDecimal{"a": 1}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if expected := "d1:a1:1e"; buf.String() != expected {
+		t.Fatalf("Expected %q, but got %q", expected, buf.String())
+	}
+}
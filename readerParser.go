@@ -4,21 +4,17 @@ import (
 	"bytes"
 	"errors"
 	"io"
-	"strconv"
 )
 
 const (
-	bufferSize      = 1024
-	minBufferSize   = 22 // The minimum buffer size to ~ store a number
-	maxEmptyReads   = 100
-	MaxStringLength = 2 << 22 // ~ 8MB
+	bufferSize    = 1024
+	minBufferSize = 22 // The minimum buffer size to ~ store a number
+	maxEmptyReads = 100
 )
 
 var (
 	// Only called by panic() when n, _ = io.Reader.Read(); n < 0
 	ErrNegativeRead = errors.New("readerParser: reader returned a negative read")
-	// Error returned if a string length is larger than 8MB (to avoid this use stringParser instead)
-	ErrLargeStringLen = errors.New("readerParser: string length are limited to ~8MB for security reasons")
 )
 
 // A bencode reader that uses an io.Reader as source
@@ -26,6 +22,8 @@ type readerParser struct {
 	buffer [bufferSize]byte
 	reader io.Reader
 	s, e   int
+	// The number of bytes read before the start of the buffer
+	consumed int64
 }
 
 // Returns the length of the buffer
@@ -39,6 +37,7 @@ func (rp *readerParser) fill() error {
 	if b == bufferSize {
 		return nil // Already full
 	}
+	rp.consumed += int64(rp.s)
 	// Swift current buffer
 	if b > 0 {
 		copy(rp.buffer[:], rp.buffer[rp.s:rp.e])
@@ -89,55 +88,64 @@ func (rp *readerParser) undoReadByte() {
 }
 
 // Reads a number until an end byte
-func (rp *readerParser) readIntTo(separator byte) (int, error) {
+func (rp *readerParser) readNumberTo(separator byte) (string, error) {
 	// Try to buffer value
 	if rp.buffered() < minBufferSize {
 		if err := rp.fill(); err != nil {
-			return 0, err
+			return "", err
 		} // else we have at least 1 item in the buffer
 	}
 	// Lookup the integer in the buffer
 	index := bytes.IndexByte(rp.buffer[rp.s:rp.e], separator)
+	if index == -1 && rp.buffered() < bufferSize {
+		// The number might continue after the buffered data
+		if err := rp.fill(); err != nil {
+			return "", err
+		}
+		index = bytes.IndexByte(rp.buffer[rp.s:rp.e], separator)
+	}
 	if index == -1 {
-		return 0, io.ErrUnexpectedEOF
+		return "", io.ErrUnexpectedEOF
 	}
-	n, err := strconv.Atoi(string(rp.buffer[rp.s : rp.s+index]))
+	number := string(rp.buffer[rp.s : rp.s+index])
 	rp.s += index + 1
-	return n, err
+	return number, nil
 }
 
 // Returns a string of a given length
 func (rp *readerParser) readString(length int) (string, error) {
-	// Check if length is reasonable
-	if length > MaxStringLength {
-		return "", ErrLargeStringLen
-	}
 	// Try to get from buffer
-	lb := rp.buffered()
 	if rp.buffered() >= length {
-		lb = length
-		rp.s += lb
-		return string(rp.buffer[rp.s-lb : rp.s]), nil
+		rp.s += length
+		return string(rp.buffer[rp.s-length : rp.s]), nil
 	}
-	// Build from scratch
-	i := 0
-	strBuf := make([]byte, length)
+	b, err := rp.readBytes(length)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Returns a new slice with the bytes of a given length
+func (rp *readerParser) readBytes(length int) ([]byte, error) {
 	// Fetch beginning from buffer
-	if lb > 0 {
-		copy(strBuf, rp.buffer[rp.s:rp.e])
-		rp.s += lb
-		i += lb
+	strBuf := make([]byte, length)
+	i := copy(strBuf, rp.buffer[rp.s:rp.e])
+	rp.s += i
+	if i == length {
+		return strBuf, nil
 	}
-	// Fetch rest dynamically
+	// Fetch rest dynamically, directly into the result
 	countEmpty := 0
 	for countEmpty <= maxEmptyReads {
 		n, err := rp.reader.Read(strBuf[i:])
 		i += n
+		rp.consumed += int64(n)
 		if i == length && (err == nil || err != io.EOF) {
-			return string(strBuf), nil
+			return strBuf, nil
 		}
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if n < 0 {
 			panic(ErrNegativeRead)
@@ -148,17 +156,48 @@ func (rp *readerParser) readString(length int) (string, error) {
 			countEmpty = 0
 		}
 	}
-	return "", io.ErrNoProgress
+	return nil, io.ErrNoProgress
 }
 
-// Returns a bencode decoder from a given string
-func NewParserFromReader(reader io.Reader) *decoder {
-	return &decoder{
-		&readerParser{
-			buffer: [bufferSize]byte{},
-			reader: reader,
-			s:      0,
-			e:      0,
-		},
+// Skips a given number of bytes without allocating them
+func (rp *readerParser) discard(length int) error {
+	for length > 0 {
+		if rp.buffered() < 1 {
+			if err := rp.fill(); err != nil {
+				return err
+			}
+		}
+		n := rp.buffered()
+		if n > length {
+			n = length
+		}
+		rp.s += n
+		length -= n
 	}
+	return nil
+}
+
+// Returns the number of bytes read so far
+func (rp *readerParser) offset() int64 {
+	return rp.consumed + int64(rp.s)
+}
+
+// Returns the bytes in the buffer that were not consumed yet
+func (rp *readerParser) unread() io.Reader {
+	return bytes.NewReader(rp.buffer[rp.s:rp.e])
+}
+
+// Returns the bytes in the buffer followed by the rest of the reader
+func (rp *readerParser) remaining() io.Reader {
+	return io.MultiReader(rp.unread(), rp.reader)
+}
+
+// Returns a bencode decoder from a given io.Reader
+func NewParserFromReader(reader io.Reader, opts ...DecoderOption) *Decoder {
+	return newDecoder(&readerParser{
+		buffer: [bufferSize]byte{},
+		reader: reader,
+		s:      0,
+		e:      0,
+	}, opts)
 }
@@ -2,7 +2,6 @@ package bencode
 
 import (
 	"io"
-	"strconv"
 	"strings"
 )
 
@@ -32,34 +31,68 @@ func (sp *stringParser) undoReadByte() {
 }
 
 // Reads a number until an end byte
-func (sp *stringParser) readIntTo(separator byte) (int, error) {
+func (sp *stringParser) readNumberTo(separator byte) (string, error) {
 	i := sp.i
 	if i >= len(sp.bencode) {
-		return 0, io.ErrUnexpectedEOF
+		return "", io.ErrUnexpectedEOF
 	}
 	index := strings.IndexByte(sp.bencode[i:], separator)
 	if index == -1 {
-		return 0, io.ErrUnexpectedEOF
+		return "", io.ErrUnexpectedEOF
 	}
 	sp.i += index + 1
-	return strconv.Atoi(sp.bencode[i : i+index])
+	return sp.bencode[i : i+index], nil
 }
 
 // Returns a string of a given length
 func (sp *stringParser) readString(length int) (string, error) {
-	sp.i += length
-	if sp.i > len(sp.bencode) {
+	// Check the length before moving, as a huge length could overflow the index
+	if length > len(sp.bencode)-sp.i {
+		sp.i = len(sp.bencode)
 		return "", io.ErrUnexpectedEOF
 	}
+	sp.i += length
 	return sp.bencode[sp.i-length : sp.i], nil
 }
 
-// Returns a bencode decoder from a given string
-func NewParserFromString(bencode string) *decoder {
-	return &decoder{
-		&stringParser{
-			bencode: bencode,
-			i:       0,
-		},
+// Returns a new slice with the bytes of a given length
+func (sp *stringParser) readBytes(length int) ([]byte, error) {
+	s, err := sp.readString(length)
+	if err != nil {
+		return nil, err
 	}
+	return []byte(s), nil
+}
+
+// Skips a given number of bytes without allocating them
+func (sp *stringParser) discard(length int) error {
+	if length > len(sp.bencode)-sp.i {
+		sp.i = len(sp.bencode)
+		return io.ErrUnexpectedEOF
+	}
+	sp.i += length
+	return nil
+}
+
+// Returns the number of bytes read so far
+func (sp *stringParser) offset() int64 {
+	return int64(sp.i)
+}
+
+// Returns the rest of the string
+func (sp *stringParser) unread() io.Reader {
+	return strings.NewReader(sp.bencode[sp.i:])
+}
+
+// Returns the rest of the string
+func (sp *stringParser) remaining() io.Reader {
+	return sp.unread()
+}
+
+// Returns a bencode decoder from a given string
+func NewParserFromString(bencode string, opts ...DecoderOption) *Decoder {
+	return newDecoder(&stringParser{
+		bencode: bencode,
+		i:       0,
+	}, opts)
 }
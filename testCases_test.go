@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"strings"
+
+	"github.com/stefanovazzocell/bencode"
 )
 
 var (
@@ -21,6 +24,7 @@ var (
 			"piece length": 262144,
 		},
 	}
+	debianTorrentSorted  = "d8:announce38:udp://tracker.publicbt.com:80/announce13:announce-listll38:udp://tracker.publicbt.com:80/announceel44:udp://tracker.openbittorrent.com:80/announceee7:comment33:Debian CD from cdimage.debian.org4:infod6:lengthi170917888e4:name30:debian-8.8.0-arm64-netinst.iso12:piece lengthi262144eee"
 	debianTorrentEncoded = "d4:infod6:lengthi170917888e12:piece lengthi262144e4:name30:debian-8.8.0-arm64-netinst.isoe8:announce38:udp://tracker.publicbt.com:80/announce13:announce-listll38:udp://tracker.publicbt.com:80/announceel44:udp://tracker.openbittorrent.com:80/announceee7:comment33:Debian CD from cdimage.debian.orge"
 	extremelyLongString  = "uhuauugdhzazmrolvdicxxuwvurgpzeuhapuoijmszsfjqdtphmbsnalffyfjiwqzzgohyarvdvqxjcggmwphfozqnkdrwrlytvcoyvjgftubrkjvytwtpscoinckjxgrojcznytayroynzezbdpalovboqhxpwhzdyaethclymjqnggapmsceegihlnwwggmrdkzkipmmwnszulpxfuohfqwrpglnpifevwjkxqkvvypfghnpoejwioltpwuvuemdcifbeerzfvjoryiimktkclvmuczikvufgkpczotqyezadluceulevcwfnwvyejnqoglzsatemozuajmdwgukmztahpzrhcfuhhwxkdbjpnogcmtnbwevozdtuchelwzudctzpvmwmextgcrdgucqaabgwapxvvcsbjebujogevwevzzmjgovqumwgexzbuskwqcjgxtusdcrclqzndieqohfeozmxbtaqrnovslakilhbpfycuhccifzxplwlatxxnklweidpcxcxlotxrzjnmnfcpmlzwahipqtokgwfqnsfwrrxdipyoygkhazlacpzeitjnnyiorrvghqmeebxfymgljynxiusvfyfrlqmqopkqkigblihufnnsuhflbtmotkkyebdvcybemxjuksnvdjqvefhgoiinyyfaxfqfyjuuvgdjqqxdeoregckydlxhagnjlxljvzagjkakdybkwdkucqmjcmlpzexcbocegrhjtgbyawgrzkljjvrqvkpflqsmocszccdnsxzqhaslavcjczosthibrnyogdxjjgyojqsautnqhrnsyuchjribqcecstjnubakzdtebduogpnbzqfismlvfrouivdzrxaucpeoocpesbzpucbtsvdfkpfitsnkxskztyoswydpkvobpjqpthpcwhuumymyknsdjycnzplksiheothiwakemcgyykrqhulfybxhhrqpnlhzvweopxuyttrdnzopaqktumewdkxhgzwkhlcafcladmhlrgaztgprkonsryirrawtpawpegztllpftyrseojsrxxrycsydvackuhlkawzzcqrikhcpwghiljgnduycrdmnqhdxgmmdnpnfhgzpebkkfhxghsfacqabwadtlbhejjrpohgxebwzdqlbelcqhotfbfevscpeauatzcictcwkavbbdvjniflfpnmnattefdgxbynirvfdptagdbvwutzuwrcqtjzoqqvpvlautvpukalgfwqsohtgesgfxlrkevrbzlmdrotrnwwnmkkefmjfjlsqmeookbipokxxfxlgdnsojrhanvniidveyntatncdrtqetjpivsfprmywzilmjreuiawotmzomccdydwmujmmzdmvaokjyltqmrpqoshqwwlmefwyujmnxiceexhwisjnxynrjdrsuoxsvhfypzuksllgpjeisnucvsimfvjlgedxcsiwxvvflblecskdbcsfissxxjedwqxehiepfbevcbfecnrenevfhenasvlbndblpwcxiqhanntdpvpkualnrrxszwwqgmpnrjxbyeevqdeowmxhewovcpbcgvadormsvmozphuzmtkfyvmeqqorrazbtyfzohegvlggacyzdzvgnotfbmjwsdltliaimjjcstmvmsubjcsitazzlovkkzbwsafbssiutlskmkcuceikjtjcdijsiodbhsaqvufhibhcvifbusfjuygtgvnxlsmuypqyzdrgpjlbyzbljjozeyhvwbifxsxmmdaywoqygamwydwuevyaixnznkbvoqydduhfkyjzsqekgcsrhgghctwsavcfrpbjnusodxjpndwwjdktzpureiomrtskzekrgabldgwmatvjwcgemefqvzvalzeyqyluvyqpiqjfudibpiafaiuafyezamuzyvsgmqlmeaublbxavuoejpmtsidrykizljpnccycwuflycxkukhruvbedhlywcvqpienowaggxpknqkzmurzbgyduyjbgojolaittdurksyjffvqkocahvtivanbclahcoyjnqcfmkzfughvbzgsighooecdrndjivdcbfvdgfyynpreqvnqrfvfayvoxijfjkocfvmfsqxpawfmhkdthqhvudzxwpdrasmobxbhvraleceiufqnulwrgldmcdowpatieyczexnykqhstnsmkzvlrsdtzieklpmdwvnumctsrgdegbilqihhxslowkxhvfqgrfuovrldlskavotpksqwxbexlrvfqyrtgcenmoxoegwwfoivpotjidrtjnddbfkjtnxjwumxodlbnddhpubsqbibxkmskyjmvbckoccsordfutdyflzejbyrisovejjtyxmqspkwvupgjjhuivkpbxkbkurawflvbyxuwjpiaysqhureokmjfsxondnkfpgohgobcgsyhdmuozvjubjqfgzwyorghftzlcjawqzkpvaysbiiyikfhuxkzlymyhumcqbvhgmrfqcgyhwrnwkitjgmoccuizkqzftwzvymvaguhivwfppyobkrdevizhfpfwlkibxzjvleuijgtakovepqrjpnhkjxlailtxblqeowfxdztwezqalodhonyuotovwdsaqmpyvswdeajckhwzqbggscphylkcqlatauyhllrojgpskqmqrqmtirnbdnmhvlxnlmequjrbrgbocytgotirqsxdbsckghnihwbpyphgaixzfqyfwdsmkotdigozvagizcnxltuczpggftohkvjvackfnpsvcuhcqfmobpufrpdswferlmraokutwyqxaxdemokkvnsngtnpaggkktmxuvtaoerulvuogccnujovctucjzkjcacqayhooyjdwgbiwawkbkgernibccljapbonjaahdqhoxwajgsaitcunxsezfafcqdhmxdxfpjxzfihsvsnxueqvwtdkilmfxrvfzahfyzoqzcvujojgkljofveoizzsnqmvjwaqwxymzqfwpjyprogptaqusycqihsykfylovtwyeonvnqoldcnsqrbikgpfcobbjgzqofwvzrmhftqtwyxqbfbegpdtgbdpmndfcrtbbxpeeouafpxpvaoboctpnctewsubcbtcojqnctrbvosbpvqvyiweqyhguxdyxlfidhwxbjwevzstlxjkqjlhkrwayhixqyhcykxrdygosebppmhonzkjatmtunujbafscymfvqunyhjgrcwwexgdzhrllztstwykzkjsjsdplxbqyhfpirnfesvgvbkveuorlxttsjqtzuklqsymhbvdufevcwynymjwkbmzbeuzinscnuszzxuzilpuktqvgvnsxpbgjsvdziakykkcnnryqafpcvyafyhkyeorlcbzrnhtkjxprknobozgbypqblryuolkzoarwqfsqbtgrgefvkllrobrwbpurmjdvmngusevviadavlclvarwwtgflzsquwwgufnhoowlgwgdorbncagheifvqyfzyyovgwgbjsoqjtuvymuzjwnoktnqsrqkyjeumghiwvhrxvjkqxrhgzuiqlrpsljvuvaozqccuqyocbtuwhxypvatnuwgklwiopopynvfimftafhhsqxxwqazpupldusdgqszopdqoltdcpdrovpiieyxifqydhhqpjksbwfyldvhygexxueihfadqbfunyjgpjoohyptamxcpnlcdulgskrscopoldwekwncpottvthjqaakdpklonbzyzqszsqjfjxfdawarxtodqwtfkxxhyslrjaizzbtduncohzytwmlsianrygwsqkizsddtvwebxukuhmjbrgyewdserjkvvapknqjxqcvpzguooxtrqoeaeykiyooaqlyzuusnybchoevdwtofwrjzfcnyrajqoyfswtxiimtseshreyiuenvuwriylwqhoxwhsabacegohhjhhfihyptnllefankmployeqshrpenrdhlftvzxlxdxdgcqwnubzpglnfhymiuzufzdnzkxbuiblclpvkpmhnmgflyontqwzwhbquuuiylmmuouylewjcwyskitnefsdzxufsbpnqqujhubybpmexfzaapvrfnsstjmqwhqvmjeahsghmeejrgpeaxahrbopkvovydmabfgbbxelvikguypjyhfnhtupyjmexlaglacpxytjcdwwgtnjxpinivhsekgprdfedpiebzjtzmaoaruikqyzhgaaopmehwacdxujhpldxzumfjtrevysmgtkqibjlzspioxaspsgjlpwfduosmbssjjyasecqaydlebtwodvryjijokmxsnylrzpqozybpjissljskovpajuvdqlhrbcpqaxhiefqoxmzzkppfgbnotboizmbgitmgxzqouzedcxxplufemyqmdccrymntzfuztuodtsjquzapjsehyxidatojfeiqszlxynykgelwneechdzavwnhgtrwirfgtawycwivzulbbsrncvxizvbkzolermjxzdmrgspelucfzothhkghtqagepdsfhfwxlrtxofbgccxsijcondbbiqlelvzbgbdyytauupoeaohimzwggbnggxronfoopdzaxaeuwqtfdsqhkzkyelkjvtalmbqwsmmwjqfcswaksqdemipycfqstsxruoavetuodrtrfhucqwbaospmjnwnzctdachgvlehlmuhubqwncwscwvnwkofmbjpwmftvubrhyhruvzfpqvlfcvdikukkqxrivdwnlfimbhdnohrwzbauahwjnlsuqwljnsmfngnswypogczzgqgxcelqwgvfsomdmhhahckzfzxmjfhrdofbcjefhmisxffcoxzvtzbfnhuoverkvfkrlkqadzustxemnrkvuyagvhxirdkesmzwajbztuykqsiqknrtdhf"
 	fedoraMagnet         = "magnet:?xt=urn:btih:LTLWT6I2S4R2DBLMR4YPFJBVU4LCOBMY&dn=Fedora-Workstation-Live-x86_64-38&tr=http%3A%2F%2Ftorrent.fedoraproject.org%3A6969%2Fannounce"
@@ -43,6 +47,7 @@ var (
 		},
 		"downloaded": map[string]interface{}{},
 	}
+	complexMapCanonical  = "d5:countd13:changePerHouri-235e4:donei2592e7:seedersi10053ee10:downloadedde10:magnetLink149:magnet:?xt=urn:btih:LTLWT6I2S4R2DBLMR4YPFJBVU4LCOBMY&dn=Fedora-Workstation-Live-x86_64-38&tr=http%3A%2F%2Ftorrent.fedoraproject.org%3A6969%2Fannounce5:peersle6:piecesl6:piece16:piece2i3ed4:sub12:No4:sub23:Yeseee"
 	complexMapTranslated = "d5:countd7:seedersi10053e4:donei2592e13:changePerHouri-235ee5:peersle6:piecesl6:piece16:piece2i3ed4:sub12:No4:sub23:Yesee10:downloadedde10:magnetLink149:magnet:?xt=urn:btih:LTLWT6I2S4R2DBLMR4YPFJBVU4LCOBMY&dn=Fedora-Workstation-Live-x86_64-38&tr=http%3A%2F%2Ftorrent.fedoraproject.org%3A6969%2Fannouncee"
 
 	invalidTestCases = []interface{}{
@@ -97,10 +102,12 @@ var (
 		"li11ele11:Hello Worlde": {11, []interface{}{}, "Hello World"},
 	}
 	mapTestCases = map[string]map[string]interface{}{
-		"de":          {},
-		"d5:hellodee": {"hello": map[string]interface{}{}},
-		// "d5:hello5:world2:hii5ee": {"hello": "world", "hi": 5},
-		// complexMapTranslated:      complexMap,
+		"de":                                 {},
+		"d5:hellodee":                        {"hello": map[string]interface{}{}},
+		"d5:hello5:world2:hii5ee":            {"hello": "world", "hi": 5},
+		"d1:a0:1:b0:1:c0:2:c\x000:1:\xff0:e": {"\xff": "", "c\x00": "", "b": "", "c": "", "a": ""},
+		complexMapCanonical:                  complexMap,
+		debianTorrentSorted:                  debianTorrent,
 	}
 	complexMapTestCases = map[string]map[string]interface{}{
 		"de":                       {},
@@ -109,6 +116,8 @@ var (
 		complexMapTranslated:       complexMap,
 		"d4:name5:Alice3:agei35ee": {"name": "Alice", "age": 35},
 		debianTorrentEncoded:       debianTorrent,
+		debianTorrentSorted:        debianTorrent,
+		complexMapCanonical:        complexMap,
 	}
 	invalidParserInputs = []string{
 		"",
@@ -133,6 +142,22 @@ var (
 		"99999999999",
 		"+100000000000000000:0",
 	}
+	strictInvalidTestCases = map[string]error{
+		"i-0e":             bencode.ErrNegativeZero,
+		"i03e":             bencode.ErrLeadingZero,
+		"i00e":             bencode.ErrLeadingZero,
+		"i+5e":             bencode.ErrPlusSign,
+		"05:hello":         bencode.ErrLeadingZero,
+		"+5:hello":         bencode.ErrPlusSign,
+		"-0:":              bencode.ErrNegativeZero,
+		"i-01e":            bencode.ErrLeadingZero,
+		"d1:bi1e1:ai2ee":   bencode.ErrUnsortedKeys,
+		"d1:ai1e1:ai2ee":   bencode.ErrDuplicateKey,
+		"ld1:b0:1:a0:ee":   bencode.ErrUnsortedKeys,
+		"d2:aa0:1:a0:e":    bencode.ErrUnsortedKeys,
+		"d1:ali01eee":      bencode.ErrLeadingZero,
+		"d1:a02:hi1:b0:ee": bencode.ErrLeadingZero,
+	}
 	invalidTypeParse = map[string]byte{
 		"":       byte('x'),
 		"x34l":   byte('x'),
@@ -150,8 +175,12 @@ var (
 		"complexMap":    complexMap,
 	}
 	fedoraMagnetParsed = fmt.Sprintf("%d:%s", len(fedoraMagnet), fedoraMagnet)
-	parserBenchmarks   = map[string]string{
+	// A get_peers response of the Mainline DHT with 8 nodes
+	dhtResponse = "d1:rd2:id20:" + strings.Repeat("i", 20) + "5:nodes208:" + strings.Repeat("n", 208) +
+		"5:token8:aoeusnth6:valuesl6:axje.u6:idhtnmee1:t2:aa1:y1:re"
+	parserBenchmarks = map[string]string{
 		"torrentString": fedoraMagnetParsed,
 		"complexMap":    complexMapTranslated,
+		"dhtResponse":   dhtResponse,
 	}
 )
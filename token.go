@@ -0,0 +1,201 @@
+package bencode
+
+import (
+	"errors"
+)
+
+var (
+	// Error for an 'e' that doesn't close a list or dictionary,
+	// or that closes a dictionary right after a key
+	ErrUnexpectedEnd = errors.New("invalid bencode: unexpected end")
+)
+
+// The type of a bencode token
+type TokenType uint8
+
+const (
+	// Not a token, returned along with errors
+	InvalidToken TokenType = iota
+	// The start of a list ('l')
+	ListStart
+	// The start of a dictionary ('d')
+	DictStart
+	// The end of a list or dictionary ('e')
+	End
+	// An integer
+	Int
+	// A string, including dictionary keys
+	String
+)
+
+// Returns the name of the token type
+func (t TokenType) String() string {
+	switch t {
+	case ListStart:
+		return "ListStart"
+	case DictStart:
+		return "DictStart"
+	case End:
+		return "End"
+	case Int:
+		return "Int"
+	case String:
+		return "String"
+	}
+	return "InvalidToken"
+}
+
+// A single bencode token
+type Token struct {
+	Type TokenType
+	// The value of an Int token
+	Int int
+	// The value of a String token
+	String string
+}
+
+// A list or dictionary opened by a token
+type tokenFrame struct {
+	// True for dictionaries, false for lists
+	dict bool
+	// True if the next token of a dictionary is a key
+	key bool
+	// The previous key of a dictionary, if any
+	prev    string
+	hasPrev bool
+}
+
+// Marks the end of a value in the current list or dictionary.
+// At the top level, checks that the input ends if trailing data is disallowed.
+func (d *Decoder) valueDone() error {
+	n := len(d.tokens)
+	if n > 0 {
+		if d.tokens[n-1].dict {
+			d.tokens[n-1].key = !d.tokens[n-1].key
+		}
+		return nil
+	}
+	if d.options.DisallowTrailingData {
+		return d.checkEnd()
+	}
+	return nil
+}
+
+// Reads the next token from the decoder.
+//
+// Lists and dictionaries are returned as a ListStart or DictStart token,
+// followed by the tokens of their content and an End token. Dictionary
+// keys are returned as String tokens. This allows processing large
+// documents one value at a time; an exported method such as AsInterface
+// or Skip can be used to read a whole value in the middle of the tokens.
+//
+// Returns io.EOF once the input ends between top-level values.
+func (d *Decoder) Token() (Token, error) {
+	t, err := d.readByte()
+	if err != nil {
+		if len(d.tokens) > 0 {
+			err = d.eofError("token", err)
+		}
+		return Token{}, err
+	}
+	var frame *tokenFrame
+	if n := len(d.tokens); n > 0 {
+		frame = &d.tokens[n-1]
+	}
+	// End of a list or dictionary
+	if t == 'e' {
+		if frame == nil || frame.dict && !frame.key {
+			return Token{}, syntaxError(d.offset()-1, "value", t, ErrUnexpectedEnd)
+		}
+		d.tokens = d.tokens[:len(d.tokens)-1]
+		d.leave()
+		if err := d.valueDone(); err != nil {
+			return Token{}, err
+		}
+		return Token{Type: End}, nil
+	}
+	// Dictionary key
+	if frame != nil && frame.dict && frame.key {
+		if t == 'i' || t == 'l' || t == 'd' {
+			return Token{}, syntaxError(d.offset()-1, "dictionary key", t, ErrInvalidType)
+		}
+		d.undoReadByte()
+		start := d.offset()
+		key, err := d.asString()
+		if err != nil {
+			return Token{}, err
+		}
+		if err := d.checkKey(frame.prev, key, frame.hasPrev, start); err != nil {
+			return Token{}, err
+		}
+		frame.prev, frame.hasPrev, frame.key = key, true, false
+		return Token{Type: String, String: key}, nil
+	}
+	// Value
+	switch t {
+	case 'i':
+		n, err := d.readIntTo('e')
+		if err != nil {
+			return Token{}, err
+		}
+		if err := d.valueDone(); err != nil {
+			return Token{}, err
+		}
+		return Token{Type: Int, Int: n}, nil
+	case 'l', 'd':
+		if err := d.enter(t); err != nil {
+			return Token{}, err
+		}
+		d.tokens = append(d.tokens, tokenFrame{dict: t == 'd', key: t == 'd'})
+		if t == 'l' {
+			return Token{Type: ListStart}, nil
+		}
+		return Token{Type: DictStart}, nil
+	}
+	d.undoReadByte()
+	s, err := d.asString()
+	if err != nil {
+		return Token{}, err
+	}
+	if err := d.valueDone(); err != nil {
+		return Token{}, err
+	}
+	return Token{Type: String, String: s}, nil
+}
+
+// Returns the type of the next token without consuming it
+func (d *Decoder) Next() (TokenType, error) {
+	t, err := d.readByte()
+	if err != nil {
+		return InvalidToken, err
+	}
+	d.undoReadByte()
+	switch {
+	case t == 'i':
+		return Int, nil
+	case t == 'l':
+		return ListStart, nil
+	case t == 'd':
+		return DictStart, nil
+	case t == 'e':
+		return End, nil
+	case '0' <= t && t <= '9' || t == '-' || t == '+':
+		return String, nil
+	}
+	return InvalidToken, syntaxError(d.offset(), "value", t, ErrInvalidType)
+}
+
+// Skips the next value, including all the content of a list or dictionary,
+// without allocating its strings.
+func (d *Decoder) Skip() error {
+	if next, err := d.Next(); err != nil {
+		return err
+	} else if next == End {
+		return syntaxError(d.offset(), "value", 'e', ErrUnexpectedEnd)
+	}
+	t, err := d.readByte()
+	if err != nil {
+		return err
+	}
+	return d.done(d.skipValue(t))
+}
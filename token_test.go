@@ -0,0 +1,214 @@
+package bencode_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+// Builds a value from the tokens of a decoder
+func valueFromTokens(decoder *bencode.Decoder, tok bencode.Token) (interface{}, error) {
+	switch tok.Type {
+	case bencode.Int:
+		return tok.Int, nil
+	case bencode.String:
+		return tok.String, nil
+	case bencode.ListStart:
+		list := []interface{}{}
+		for {
+			tok, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			if tok.Type == bencode.End {
+				return list, nil
+			}
+			v, err := valueFromTokens(decoder, tok)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+	case bencode.DictStart:
+		dict := map[string]interface{}{}
+		for {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			if key.Type == bencode.End {
+				return dict, nil
+			}
+			tok, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := valueFromTokens(decoder, tok)
+			if err != nil {
+				return nil, err
+			}
+			dict[key.String] = v
+		}
+	}
+	return nil, fmt.Errorf("unexpected token %v", tok.Type)
+}
+
+func TestToken(t *testing.T) {
+	tester := func(decoder *bencode.Decoder, testCase string, expected interface{}) {
+		tok, err := decoder.Token()
+		if err != nil {
+			t.Fatalf("Failed to read first token of %q: %v", testCase, err)
+		}
+		actual, err := valueFromTokens(decoder, tok)
+		if err != nil {
+			t.Fatalf("Failed to read tokens of %q: %v", testCase, err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected %v from tokens of %q, but got %v", expected, testCase, actual)
+		}
+		if tok, err := decoder.Token(); err != io.EOF {
+			t.Fatalf("Expected io.EOF after %q, but got (%v, %v)", testCase, tok, err)
+		}
+	}
+	for _, str := range stringsTestCases {
+		test := fmt.Sprintf("%d:%s", len(str), str)
+		tester(bencode.NewParserFromString(test), test, str)
+		tester(bencode.NewParserFromReader(strings.NewReader(test)), test, str)
+	}
+	for test, expected := range intsTestCases {
+		tester(bencode.NewParserFromString(test), test, expected)
+		tester(bencode.NewParserFromReader(strings.NewReader(test)), test, expected)
+	}
+	for test, expected := range slicesTestCases {
+		tester(bencode.NewParserFromString(test), test, expected)
+		tester(bencode.NewParserFromReader(strings.NewReader(test)), test, expected)
+	}
+	for test, expected := range complexMapTestCases {
+		tester(bencode.NewParserFromString(test), test, expected)
+		tester(bencode.NewParserFromReader(strings.NewReader(test)), test, expected)
+	}
+	// Invalid token streams
+	for invalid, expected := range map[string]error{
+		"e":          bencode.ErrUnexpectedEnd,
+		"lee":        bencode.ErrUnexpectedEnd,
+		"d1:ae":      bencode.ErrUnexpectedEnd,
+		"di1ei2ee":   bencode.ErrInvalidType,
+		"l":          io.ErrUnexpectedEOF,
+		"d1:al":      io.ErrUnexpectedEOF,
+		"llllllllle": bencode.ErrMaxDepth,
+	} {
+		decoder := bencode.NewParserFromString(invalid, bencode.WithMaxDepth(8))
+		var err error
+		for err == nil {
+			_, err = decoder.Token()
+		}
+		if !errors.Is(err, expected) {
+			t.Fatalf("Expected tokens of %q to fail with %v, but got %v", invalid, expected, err)
+		}
+	}
+	// Strict mode applies to keys
+	for invalid, expected := range strictInvalidTestCases {
+		decoder := bencode.NewParserFromString(invalid, bencode.WithStrict())
+		var err error
+		for err == nil {
+			_, err = decoder.Token()
+		}
+		if !errors.Is(err, expected) {
+			t.Fatalf("Expected tokens of %q to fail with %v, but got %v", invalid, expected, err)
+		}
+	}
+}
+
+func TestTokenMixed(t *testing.T) {
+	// Read a dictionary key by key, decoding and skipping values
+	decoder := bencode.NewParserFromReader(strings.NewReader(debianTorrentSorted + "i42e"))
+	if tok, err := decoder.Token(); err != nil || tok.Type != bencode.DictStart {
+		t.Fatalf("Expected DictStart, but got (%v, %v)", tok, err)
+	}
+	keys := []string{}
+	for {
+		if next, err := decoder.Next(); err != nil {
+			t.Fatalf("Next returned error: %v", err)
+		} else if next == bencode.End {
+			break
+		} else if next != bencode.String {
+			t.Fatalf("Expected String key, but got %v", next)
+		}
+		key, err := decoder.Token()
+		if err != nil {
+			t.Fatalf("Token returned error: %v", err)
+		}
+		keys = append(keys, key.String)
+		switch key.String {
+		case "info":
+			var info testInfo
+			if err := decoder.Decode(&info); err != nil {
+				t.Fatalf("Decode returned error: %v", err)
+			}
+			if !reflect.DeepEqual(&info, debianTorrentStruct.Info) {
+				t.Fatalf("Expected %v, but got %v", debianTorrentStruct.Info, info)
+			}
+		case "comment":
+			if comment, err := decoder.AsString(); err != nil || comment != debianTorrentStruct.Comment {
+				t.Fatalf("Expected %q, but got (%q, %v)", debianTorrentStruct.Comment, comment, err)
+			}
+		default:
+			if err := decoder.Skip(); err != nil {
+				t.Fatalf("Skip returned error: %v", err)
+			}
+		}
+	}
+	if expected := []string{"announce", "announce-list", "comment", "info"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("Expected keys %v, but got %v", expected, keys)
+	}
+	if tok, err := decoder.Token(); err != nil || tok.Type != bencode.End {
+		t.Fatalf("Expected End, but got (%v, %v)", tok, err)
+	}
+	if err := decoder.Skip(); err != nil {
+		t.Fatalf("Skip returned error: %v", err)
+	}
+	if err := decoder.Skip(); err != io.EOF {
+		t.Fatalf("Expected io.EOF, but got %v", err)
+	}
+	// Skip can't leave the current list
+	decoder = bencode.NewParserFromString("le")
+	decoder.Token()
+	if err := decoder.Skip(); !errors.Is(err, bencode.ErrUnexpectedEnd) {
+		t.Fatalf("Expected ErrUnexpectedEnd, but got %v", err)
+	}
+	if tok, err := decoder.Token(); err != nil || tok.Type != bencode.End {
+		t.Fatalf("Expected End after failed Skip, but got (%v, %v)", tok, err)
+	}
+}
+
+func TestTokenTypeString(t *testing.T) {
+	for tokenType, expected := range map[bencode.TokenType]string{
+		bencode.InvalidToken: "InvalidToken",
+		bencode.ListStart:    "ListStart",
+		bencode.DictStart:    "DictStart",
+		bencode.End:          "End",
+		bencode.Int:          "Int",
+		bencode.String:       "String",
+	} {
+		if tokenType.String() != expected {
+			t.Fatalf("Expected %q, but got %q", expected, tokenType.String())
+		}
+	}
+}
+
+func BenchmarkToken(b *testing.B) {
+	for benchName, testString := range parserBenchmarks {
+		b.Run(benchName, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				decoder := bencode.NewParserFromString(testString)
+				for _, err := decoder.Token(); err == nil; _, err = decoder.Token() {
+				}
+			}
+		})
+	}
+}
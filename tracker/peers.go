@@ -0,0 +1,157 @@
+package tracker
+
+import (
+	"encoding/binary"
+	"net/netip"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+const (
+	// The size of an IPv4 peer in a compact peer list
+	compactPeerSize = 4 + 2
+	// The size of an IPv6 peer in a compact peer list
+	compactPeer6Size = 16 + 2
+)
+
+// A peer returned by a tracker
+type Peer struct {
+	// The peer id, only set in non-compact responses
+	ID []byte
+	// The address of the peer; only its port is set if Host is
+	Addr netip.AddrPort
+	// The DNS name of the peer, only set in non-compact responses that
+	// give a hostname instead of an IP address
+	Host string
+}
+
+// The peers of an announce response.
+//
+// They are decoded from either a compact string of IPv4 peers (BEP 23) or
+// a list of dictionaries. They are encoded as a compact string if all the
+// peers are IPv4 and have no peer id, or as a list of dictionaries otherwise.
+type Peers []Peer
+
+// The IPv6 peers of an announce response (BEP 7).
+//
+// They are decoded from either a compact string of IPv6 peers or a list of
+// dictionaries. They are encoded as a compact string if all the peers are
+// IPv6 and have no peer id, or as a list of dictionaries otherwise.
+type Peers6 []Peer
+
+// A peer in a non-compact response
+type dictPeer struct {
+	ID   []byte `bencode:"peer id,omitempty"`
+	IP   string `bencode:"ip"`
+	Port uint16 `bencode:"port"`
+}
+
+// Returns the bencode encoding of the peers
+func (p Peers) MarshalBencode() ([]byte, error) {
+	return marshalPeers(p, compactPeerSize)
+}
+
+// Decodes compact or non-compact peers
+func (p *Peers) UnmarshalBencode(data []byte) error {
+	peers, err := unmarshalPeers(data, compactPeerSize)
+	*p = peers
+	return err
+}
+
+// Returns the bencode encoding of the peers
+func (p Peers6) MarshalBencode() ([]byte, error) {
+	return marshalPeers(p, compactPeer6Size)
+}
+
+// Decodes compact or non-compact peers
+func (p *Peers6) UnmarshalBencode(data []byte) error {
+	peers, err := unmarshalPeers(data, compactPeer6Size)
+	*p = peers
+	return err
+}
+
+// Returns true if a peer can be written in a compact list of a given size
+func canCompact(peer Peer, size int) bool {
+	if peer.ID != nil || peer.Host != "" {
+		return false
+	}
+	if size == compactPeerSize {
+		return peer.Addr.Addr().Unmap().Is4()
+	}
+	return peer.Addr.Addr().Is6() && !peer.Addr.Addr().Is4In6()
+}
+
+// Encodes peers in their compact form of a given size if possible
+func marshalPeers(peers []Peer, size int) ([]byte, error) {
+	compact := true
+	for _, peer := range peers {
+		if !canCompact(peer, size) {
+			compact = false
+			break
+		}
+	}
+	if !compact {
+		list := make([]dictPeer, len(peers))
+		for i, peer := range peers {
+			list[i] = dictPeer{
+				ID:   peer.ID,
+				IP:   peer.Addr.Addr().Unmap().String(),
+				Port: peer.Addr.Port(),
+			}
+			if peer.Host != "" {
+				list[i].IP = peer.Host
+			}
+		}
+		return bencode.Marshal(list)
+	}
+	b := make([]byte, 0, len(peers)*size)
+	for _, peer := range peers {
+		if size == compactPeerSize {
+			ip := peer.Addr.Addr().Unmap().As4()
+			b = append(b, ip[:]...)
+		} else {
+			ip := peer.Addr.Addr().As16()
+			b = append(b, ip[:]...)
+		}
+		b = binary.BigEndian.AppendUint16(b, peer.Addr.Port())
+	}
+	return bencode.Marshal(b)
+}
+
+// Decodes compact peers of a given size or a list of peer dictionaries
+func unmarshalPeers(data []byte, size int) ([]Peer, error) {
+	if len(data) > 0 && data[0] == 'l' {
+		list := []dictPeer{}
+		if err := bencode.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		peers := make([]Peer, len(list))
+		for i, p := range list {
+			if p.IP == "" {
+				return nil, ErrInvalidPeers
+			}
+			ip, err := netip.ParseAddr(p.IP)
+			if err != nil {
+				// A hostname, which BEP 3 allows
+				peers[i] = Peer{ID: p.ID, Addr: netip.AddrPortFrom(netip.Addr{}, p.Port), Host: p.IP}
+				continue
+			}
+			peers[i] = Peer{ID: p.ID, Addr: netip.AddrPortFrom(ip, p.Port)}
+		}
+		return peers, nil
+	}
+	var b []byte
+	if err := bencode.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	if len(b)%size != 0 {
+		return nil, ErrInvalidPeers
+	}
+	peers := make([]Peer, 0, len(b)/size)
+	for ; len(b) > 0; b = b[size:] {
+		ip, _ := netip.AddrFromSlice(b[:size-2])
+		port := binary.BigEndian.Uint16(b[size-2:])
+		peers = append(peers, Peer{Addr: netip.AddrPortFrom(ip, port)})
+	}
+	return peers, nil
+}
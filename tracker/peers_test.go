@@ -0,0 +1,69 @@
+package tracker_test
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+	"github.com/stefanovazzocell/bencode/tracker"
+)
+
+var (
+	testPeerV4     = tracker.Peer{Addr: netip.MustParseAddrPort("10.0.0.1:6881")}
+	testPeerV6     = tracker.Peer{Addr: netip.MustParseAddrPort("[2001:db8::1]:51413")}
+	testPeerID     = tracker.Peer{ID: []byte("-XX0001-abcdefghijkl"), Addr: netip.MustParseAddrPort("192.168.1.2:80")}
+	testPeerHost   = tracker.Peer{Addr: netip.AddrPortFrom(netip.Addr{}, 6881), Host: "peer.example.com"}
+	peersTestCases = map[string]tracker.Peers{
+		"0:":                         {},
+		"6:\x0a\x00\x00\x01\x1a\xe1": {testPeerV4},
+		"12:\x0a\x00\x00\x01\x1a\xe1\x0a\x00\x00\x01\x1a\xe1":                 {testPeerV4, testPeerV4},
+		"ld2:ip11:192.168.1.27:peer id20:-XX0001-abcdefghijkl4:porti80eee":    {testPeerID},
+		"ld2:ip8:10.0.0.14:porti6881eed2:ip11:2001:db8::14:porti51413eee":     {testPeerV4, testPeerV6},
+		"ld2:ip16:peer.example.com4:porti6881eed2:ip8:10.0.0.14:porti6881eee": {testPeerHost, testPeerV4},
+	}
+	peers6TestCases = map[string]tracker.Peers6{
+		"18:\x20\x01\x0d\xb8\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\xc8\xd5": {testPeerV6},
+		"ld2:ip8:10.0.0.14:porti6881eee":                                              {testPeerV4},
+	}
+	invalidPeers = []string{
+		"5:abcde",
+		"i1e",
+		"ld2:ip0:4:porti1eee",
+		"ld2:ip8:10.0.0.14:porti65536eee",
+		"l1:xe",
+	}
+)
+
+func TestPeers(t *testing.T) {
+	for encoded, expected := range peersTestCases {
+		var peers tracker.Peers
+		if err := bencode.Unmarshal([]byte(encoded), &peers); err != nil {
+			t.Fatalf("Unmarshal of %q returned error: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(peers, expected) {
+			t.Fatalf("Expected %v from %q, got %v", expected, encoded, peers)
+		}
+		if actual, err := bencode.Marshal(peers); err != nil || string(actual) != encoded {
+			t.Fatalf("Expected %q, got (%q, %v)", encoded, actual, err)
+		}
+	}
+	for encoded, expected := range peers6TestCases {
+		var peers tracker.Peers6
+		if err := bencode.Unmarshal([]byte(encoded), &peers); err != nil {
+			t.Fatalf("Unmarshal of %q returned error: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(peers, expected) {
+			t.Fatalf("Expected %v from %q, got %v", expected, encoded, peers)
+		}
+		if actual, err := bencode.Marshal(peers); err != nil || string(actual) != encoded {
+			t.Fatalf("Expected %q, got (%q, %v)", encoded, actual, err)
+		}
+	}
+	for _, invalid := range invalidPeers {
+		var peers tracker.Peers
+		if err := bencode.Unmarshal([]byte(invalid), &peers); err == nil {
+			t.Fatalf("Expected Unmarshal of %q to fail, got %v", invalid, peers)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package tracker encodes and decodes the responses of HTTP BitTorrent
+// trackers to announce (BEP 3, BEP 23) and scrape (BEP 48) requests.
+package tracker
+
+import (
+	"errors"
+	"io"
+
+	"github.com/stefanovazzocell/bencode"
+	"github.com/stefanovazzocell/bencode/metainfo"
+)
+
+var (
+	// Error for a compact peer list with a length that is not a multiple
+	// of the size of a peer, or a peer with an invalid address
+	ErrInvalidPeers = errors.New("tracker: invalid peers")
+)
+
+// The response of a tracker to an announce request.
+// Trackers that reject a request only set FailureReason, and the other
+// fields are not encoded in that case.
+type AnnounceResponse struct {
+	FailureReason  string `bencode:"failure reason,omitempty"`
+	WarningMessage string `bencode:"warning message,omitempty"`
+	// The number of seconds to wait between regular announces
+	Interval int64 `bencode:"interval"`
+	// The minimum number of seconds to wait between announces
+	MinInterval int64  `bencode:"min interval,omitempty"`
+	TrackerID   string `bencode:"tracker id,omitempty"`
+	// The number of seeders
+	Complete int64 `bencode:"complete"`
+	// The number of leechers
+	Incomplete int64 `bencode:"incomplete"`
+	// The IPv4 peers, or all the peers in a non-compact response
+	Peers Peers `bencode:"peers"`
+	// The IPv6 peers of a compact response (BEP 7)
+	Peers6 Peers6 `bencode:"peers6,omitempty"`
+}
+
+// The fields of an announce response, without its MarshalBencode method
+type announceResponse AnnounceResponse
+
+// The response of a tracker to a scrape request
+type ScrapeResponse struct {
+	FailureReason string `bencode:"failure reason,omitempty"`
+	// The statistics of each torrent by raw 20-byte info-hash
+	Files map[string]ScrapeFile `bencode:"files"`
+}
+
+// The statistics of a torrent in a scrape response
+type ScrapeFile struct {
+	// The number of seeders
+	Complete int64 `bencode:"complete"`
+	// The number of completed downloads
+	Downloaded int64 `bencode:"downloaded"`
+	// The number of leechers
+	Incomplete int64  `bencode:"incomplete"`
+	Name       string `bencode:"name,omitempty"`
+}
+
+// Reads an announce response from a reader
+func ParseAnnounce(r io.Reader, opts ...bencode.DecoderOption) (*AnnounceResponse, error) {
+	resp := new(AnnounceResponse)
+	if err := bencode.NewDecoder(r, opts...).Decode(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Returns the bencode encoding of the response, which only has the
+// failure reason if set
+func (resp AnnounceResponse) MarshalBencode() ([]byte, error) {
+	if resp.FailureReason != "" {
+		return bencode.Marshal(struct {
+			FailureReason string `bencode:"failure reason"`
+		}{resp.FailureReason})
+	}
+	return bencode.Marshal(announceResponse(resp))
+}
+
+// Writes the announce response to a writer
+func (resp *AnnounceResponse) Write(w io.Writer) error {
+	return bencode.NewEncoder(w).Encode(resp)
+}
+
+// Reads a scrape response from a reader
+func ParseScrape(r io.Reader, opts ...bencode.DecoderOption) (*ScrapeResponse, error) {
+	resp := new(ScrapeResponse)
+	if err := bencode.NewDecoder(r, opts...).Decode(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Writes the scrape response to a writer
+func (resp *ScrapeResponse) Write(w io.Writer) error {
+	if resp.FailureReason != "" {
+		// A failed scrape has no files dictionary
+		return bencode.NewEncoder(w).Encode(struct {
+			FailureReason string `bencode:"failure reason"`
+		}{resp.FailureReason})
+	}
+	return bencode.NewEncoder(w).Encode(resp)
+}
+
+// Returns the statistics of a torrent, if present
+func (resp *ScrapeResponse) File(infoHash metainfo.Hash) (ScrapeFile, bool) {
+	f, ok := resp.Files[string(infoHash[:])]
+	return f, ok
+}
+
+// Sets the statistics of a torrent
+func (resp *ScrapeResponse) SetFile(infoHash metainfo.Hash, f ScrapeFile) {
+	if resp.Files == nil {
+		resp.Files = map[string]ScrapeFile{}
+	}
+	resp.Files[string(infoHash[:])] = f
+}
@@ -0,0 +1,123 @@
+package tracker_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+	"github.com/stefanovazzocell/bencode/metainfo"
+	"github.com/stefanovazzocell/bencode/tracker"
+)
+
+var (
+	testInfoHash      = metainfo.Hash{0xaa, 0xbb, 19: 0xff}
+	announceTestCases = map[string]tracker.AnnounceResponse{
+		"d14:failure reason9:not founde": {FailureReason: "not found"},
+		"d8:completei5e10:incompletei2e8:intervali1800e12:min intervali60e5:peers6:\x0a\x00\x00\x01\x1a\xe16:peers618:\x20\x01\x0d\xb8\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\xc8\xd510:tracker id3:abc15:warning message4:slowe": {
+			WarningMessage: "slow",
+			Interval:       1800,
+			MinInterval:    60,
+			TrackerID:      "abc",
+			Complete:       5,
+			Incomplete:     2,
+			Peers:          tracker.Peers{testPeerV4},
+			Peers6:         tracker.Peers6{testPeerV6},
+		},
+		"d8:completei0e10:incompletei0e8:intervali900e5:peersld2:ip11:192.168.1.27:peer id20:-XX0001-abcdefghijkl4:porti80eeee": {
+			Interval: 900,
+			Peers:    tracker.Peers{testPeerID},
+		},
+		"d8:completei0e10:incompletei0e8:intervali60e5:peersld2:ip16:peer.example.com4:porti6881eeee": {
+			Interval: 60,
+			Peers:    tracker.Peers{testPeerHost},
+		},
+	}
+	scrapeTestCases = map[string]tracker.ScrapeResponse{
+		"d14:failure reason6:no waye": {FailureReason: "no way"},
+		"d5:filesdee":                 {Files: map[string]tracker.ScrapeFile{}},
+		"d5:filesd20:" + string(testInfoHash[:]) + "d8:completei3e10:downloadedi10e10:incompletei1e4:name1:xeee": {
+			Files: map[string]tracker.ScrapeFile{
+				string(testInfoHash[:]): {Complete: 3, Downloaded: 10, Incomplete: 1, Name: "x"},
+			},
+		},
+	}
+)
+
+func TestAnnounce(t *testing.T) {
+	for encoded, expected := range announceTestCases {
+		resp, err := tracker.ParseAnnounce(strings.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("ParseAnnounce of %q returned error: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(*resp, expected) {
+			t.Fatalf("Expected %+v, got %+v", expected, *resp)
+		}
+		buf := bytes.Buffer{}
+		if err := resp.Write(&buf); err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+		if buf.String() != encoded {
+			t.Fatalf("Expected %q, got %q", encoded, buf.String())
+		}
+	}
+	for _, invalid := range []string{"", "le", "d5:peers5:abcdee", "d8:intervali1.5ee"} {
+		if resp, err := tracker.ParseAnnounce(strings.NewReader(invalid)); err == nil {
+			t.Fatalf("Expected ParseAnnounce of %q to fail, got %+v", invalid, resp)
+		}
+	}
+}
+
+func TestAnnounceRequiredKeys(t *testing.T) {
+	// A success response without peers still has an interval and peers
+	encoded, err := bencode.Marshal(tracker.AnnounceResponse{Interval: 1800})
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	expected := "d8:completei0e10:incompletei0e8:intervali1800e5:peers0:e"
+	if string(encoded) != expected {
+		t.Fatalf("Expected %q, got %q", expected, encoded)
+	}
+	// A failure response only has the failure reason
+	encoded, err = bencode.Marshal(tracker.AnnounceResponse{FailureReason: "banned", Interval: 1800, Peers: tracker.Peers{testPeerV4}})
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if expected := "d14:failure reason6:bannede"; string(encoded) != expected {
+		t.Fatalf("Expected %q, got %q", expected, encoded)
+	}
+}
+
+func TestScrape(t *testing.T) {
+	for encoded, expected := range scrapeTestCases {
+		resp, err := tracker.ParseScrape(strings.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("ParseScrape of %q returned error: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(*resp, expected) {
+			t.Fatalf("Expected %+v, got %+v", expected, *resp)
+		}
+		buf := bytes.Buffer{}
+		if err := resp.Write(&buf); err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+		if buf.String() != encoded {
+			t.Fatalf("Expected %q, got %q", encoded, buf.String())
+		}
+	}
+	// Access files by info-hash
+	resp := tracker.ScrapeResponse{}
+	if _, ok := resp.File(testInfoHash); ok {
+		t.Fatalf("Expected no file in an empty response")
+	}
+	resp.SetFile(testInfoHash, tracker.ScrapeFile{Complete: 1})
+	if f, ok := resp.File(testInfoHash); !ok || f.Complete != 1 {
+		t.Fatalf("Expected the file that was set, got (%+v, %v)", f, ok)
+	}
+	// A successful scrape always has files
+	buf := bytes.Buffer{}
+	if err := (&tracker.ScrapeResponse{}).Write(&buf); err != nil || buf.String() != "d5:filesdee" {
+		t.Fatalf("Expected an empty files dictionary, got (%q, %v)", buf.String(), err)
+	}
+}
@@ -0,0 +1,343 @@
+package bencode
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	// Error for when Unmarshal or Decode is not given a non-nil pointer
+	ErrInvalidUnmarshal = errors.New("invalid unmarshal target: a non-nil pointer is required")
+)
+
+// Parses the bencoded data and stores the result in the value pointed to by v.
+//
+// Dictionaries are decoded into structs (matching keys to field names or
+// `bencode:"name"` tags) or maps with string keys, lists into slices or
+// arrays, strings into strings, []byte or byte arrays and integers into
+// any integer type or big.Int. Unknown keys are ignored. Values
+// implementing Unmarshaler, such as RawMessage, are given their exact
+// original bytes. See Marshal for the reverse mapping.
+func Unmarshal(data []byte, v interface{}) error {
+	return NewParserFromString(string(data)).Decode(v)
+}
+
+// Reads a single value from the decoder and stores it in the value pointed to by v.
+// See Unmarshal for details.
+func (d *Decoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrInvalidUnmarshal
+	}
+	t, err := d.readByte()
+	if err != nil {
+		return err
+	}
+	return d.done(d.decodeValue(t, rv.Elem()))
+}
+
+// Returns an error for when a bencode value of type t, which has just
+// been read, can't be stored in v
+func (d *Decoder) typeError(t byte, v reflect.Value) error {
+	kind := "string"
+	switch t {
+	case 'i':
+		kind = "integer"
+	case 'l':
+		kind = "list"
+	case 'd':
+		kind = "dictionary"
+	}
+	err := fmt.Errorf("%w: can't decode %s into %s", ErrInvalidType, kind, v.Type())
+	return syntaxError(d.offset()-1, v.Type().String(), t, err)
+}
+
+// Returns an error for an integer starting at a given offset that
+// can't be stored in v
+func overflowError(number string, start int64, v reflect.Value) error {
+	err := fmt.Errorf("%w: %s overflows %s", ErrInvalidType, number, v.Type())
+	return syntaxError(start, v.Type().String(), 0, err)
+}
+
+// Returns an overflow error if err is caused by a number out of range,
+// or err otherwise
+func rangeError(err error, start int64, v reflect.Value) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) && numErr.Err == strconv.ErrRange {
+		return overflowError(numErr.Num, start, v)
+	}
+	return err
+}
+
+// Reads a string from the decoder.
+// Expects a type byte to be provided.
+func (d *Decoder) decodeString(t byte, v reflect.Value) (string, error) {
+	if t == 'i' || t == 'l' || t == 'd' {
+		return "", d.typeError(t, v)
+	}
+	d.undoReadByte()
+	return d.asString()
+}
+
+// Reads a string from the decoder as bytes.
+// Expects a type byte to be provided.
+func (d *Decoder) decodeBytes(t byte, v reflect.Value) ([]byte, error) {
+	if t == 'i' || t == 'l' || t == 'd' {
+		return nil, d.typeError(t, v)
+	}
+	d.undoReadByte()
+	return d.asBytes()
+}
+
+// Reads a value from the decoder into a reflected value.
+// Expects a type byte to be provided.
+func (d *Decoder) decodeValue(t byte, v reflect.Value) error {
+	// Follow pointers, allocating as needed
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Type() == bigIntType {
+		if t != 'i' {
+			return d.typeError(t, v)
+		}
+		n, err := d.readBigInt()
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(n).Elem())
+		return nil
+	}
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(unmarshalerType) {
+		raw, err := d.appendRaw(nil, t)
+		if err != nil {
+			return err
+		}
+		return v.Addr().Interface().(Unmarshaler).UnmarshalBencode(raw)
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+		}
+		obj, err := d.asInterface(t)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(obj))
+	case reflect.String:
+		s, err := d.decodeString(t, v)
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t != 'i' {
+			return d.typeError(t, v)
+		}
+		start := d.offset()
+		n, err := d.readInt64()
+		if err != nil {
+			return rangeError(err, start, v)
+		}
+		if v.OverflowInt(n) {
+			return overflowError(strconv.FormatInt(n, 10), start, v)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if t != 'i' {
+			return d.typeError(t, v)
+		}
+		start := d.offset()
+		n, err := d.readUint64()
+		if err != nil {
+			return rangeError(err, start, v)
+		}
+		if v.OverflowUint(n) {
+			return overflowError(strconv.FormatUint(n, 10), start, v)
+		}
+		v.SetUint(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && t != 'l' {
+			b, err := d.decodeBytes(t, v)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		return d.decodeSlice(t, v)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && t != 'l' {
+			start := d.offset() - 1
+			b, err := d.decodeBytes(t, v)
+			if err != nil {
+				return err
+			}
+			if len(b) != v.Len() {
+				err := fmt.Errorf("%w: can't decode string of length %d into %s", ErrInvalidType, len(b), v.Type())
+				return syntaxError(start, v.Type().String(), t, err)
+			}
+			reflect.Copy(v, reflect.ValueOf(b))
+			return nil
+		}
+		return d.decodeArray(t, v)
+	case reflect.Map:
+		return d.decodeMap(t, v)
+	case reflect.Struct:
+		return d.decodeStruct(t, v)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+	return nil
+}
+
+// Reads a list from the decoder into a reflected slice.
+// Expects a type byte to be provided.
+func (d *Decoder) decodeSlice(t byte, v reflect.Value) error {
+	if t != 'l' {
+		return d.typeError(t, v)
+	}
+	if err := d.enter(t); err != nil {
+		return err
+	}
+	defer d.leave()
+	slice := reflect.MakeSlice(v.Type(), 0, 0)
+	for {
+		t, err := d.readByteIn("list item or end")
+		if err != nil {
+			return err
+		}
+		if t == 'e' {
+			break
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := d.decodeValue(t, elem); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	v.Set(slice)
+	return nil
+}
+
+// Reads a list from the decoder into a reflected array.
+// Extra elements are discarded and missing ones are zeroed.
+// Expects a type byte to be provided.
+func (d *Decoder) decodeArray(t byte, v reflect.Value) error {
+	if t != 'l' {
+		return d.typeError(t, v)
+	}
+	if err := d.enter(t); err != nil {
+		return err
+	}
+	defer d.leave()
+	i := 0
+	for ; ; i++ {
+		t, err := d.readByteIn("list item or end")
+		if err != nil {
+			return err
+		}
+		if t == 'e' {
+			break
+		}
+		if i >= v.Len() {
+			if err := d.skipValue(t); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeValue(t, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	for ; i < v.Len(); i++ {
+		v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+	}
+	return nil
+}
+
+// Reads a dictionary from the decoder into a reflected map.
+// Expects a type byte to be provided.
+func (d *Decoder) decodeMap(t byte, v reflect.Value) error {
+	if t != 'd' {
+		return d.typeError(t, v)
+	}
+	if err := d.enter(t); err != nil {
+		return err
+	}
+	defer d.leave()
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	prev := ""
+	for i := 0; ; i++ {
+		// Read key
+		key, end, err := d.readKey(prev, i > 0)
+		if err != nil {
+			return err
+		} else if end {
+			break
+		}
+		prev = key
+		// Read value
+		t, err := d.readByteIn("dictionary value")
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := d.decodeValue(t, elem); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+	}
+	return nil
+}
+
+// Reads a dictionary from the decoder into a reflected struct.
+// Expects a type byte to be provided.
+func (d *Decoder) decodeStruct(t byte, v reflect.Value) error {
+	if t != 'd' {
+		return d.typeError(t, v)
+	}
+	if err := d.enter(t); err != nil {
+		return err
+	}
+	defer d.leave()
+	fields := cachedFields(v.Type())
+	prev := ""
+	for i := 0; ; i++ {
+		// Read key
+		key, end, err := d.readKey(prev, i > 0)
+		if err != nil {
+			return err
+		} else if end {
+			break
+		}
+		prev = key
+		// Read value
+		t, err := d.readByteIn("dictionary value")
+		if err != nil {
+			return err
+		}
+		f, ok := fields.byName[key]
+		if !ok {
+			// Unknown key, discard the value
+			if err := d.skipValue(t); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeValue(t, v.FieldByIndex(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
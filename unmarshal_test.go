@@ -0,0 +1,126 @@
+package bencode_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stefanovazzocell/bencode"
+)
+
+var (
+	private            = uint8(1)
+	unmarshalTestCases = map[string]interface{}{
+		"i-3e":                             -3,
+		"i200e":                            uint8(200),
+		"5:hello":                          "hello",
+		"5:bytes":                          []byte("bytes"),
+		"3:abc":                            [3]byte{'a', 'b', 'c'},
+		"li1ei2ee":                         []int{1, 2},
+		"li1ei2ei3ee":                      [2]int{1, 2},
+		"li1ee":                            [2]int{1, 0},
+		"d1:ai1e1:bi2ee":                   map[string]uint{"b": 2, "a": 1},
+		"d1:ai1e1:b1:xe":                   map[string]interface{}{"b": "x", "a": 1},
+		"d1:a1:xe":                         yesNoMap{"a": "x"},
+		debianTorrentEncoded:               debianTorrentStruct,
+		debianTorrentSorted:                debianTorrentStruct,
+		complexMapTranslated:               complexMap,
+		"i9223372036854775807e":            int64(math.MaxInt64),
+		"i18446744073709551615e":           uint64(math.MaxUint64),
+		"i-0e":                             uint(0),
+		"i123456789012345678901234567890e": *bigInt("123456789012345678901234567890"),
+		"d1:ni-5ee": struct {
+			N *big.Int `bencode:"n"`
+		}{big.NewInt(-5)},
+		"d7:privatei1e4:name1:xe": testInfo{Name: "x", Private: &private},
+		"d4:Name3:Bob5:array3:\x00\x01\x025:bytes0:6:lengthi7e4:pathl1:aee": testEmbedded{
+			testFile: testFile{Length: 7, Path: []string{"a"}},
+			Name:     "Bob",
+			Bytes:    []byte{},
+			Array:    [3]byte{0, 1, 2},
+		},
+	}
+	invalidUnmarshalTestCases = map[string]interface{}{
+		"i256e":                  uint8(0),
+		"i-1e":                   uint(0),
+		"i1e":                    "",
+		"1:a":                    0,
+		"le":                     map[string]int{},
+		"de":                     []int{},
+		"4:abcd":                 [3]byte{},
+		"li1ee":                  testFile{},
+		"d6:lengthi1ee":          map[int]int{},
+		"d1:Ai1ee":               struct{ A bool }{},
+		"i18446744073709551616e": uint64(0),
+		"i9223372036854775808e":  int64(0),
+		"3:abc":                  big.Int{},
+	}
+)
+
+func TestUnmarshal(t *testing.T) {
+	for test, expected := range unmarshalTestCases {
+		t.Logf("Test case %q into %T", test, expected)
+		actual := reflect.New(reflect.TypeOf(expected))
+		if err := bencode.Unmarshal([]byte(test), actual.Interface()); err != nil {
+			t.Fatalf("Unmarshal returned error: %v", err)
+		}
+		if !reflect.DeepEqual(actual.Elem().Interface(), expected) {
+			t.Fatalf("Expected %v, but got %v", expected, actual.Elem().Interface())
+		}
+		// Decode from a reader as well
+		actual = reflect.New(reflect.TypeOf(expected))
+		if err := bencode.NewParserFromReader(strings.NewReader(test)).Decode(actual.Interface()); err != nil {
+			t.Fatalf("Decode returned error: %v", err)
+		}
+		if !reflect.DeepEqual(actual.Elem().Interface(), expected) {
+			t.Fatalf("Expected %v from reader, but got %v", expected, actual.Elem().Interface())
+		}
+	}
+	for test, v := range invalidUnmarshalTestCases {
+		t.Logf("Invalid test case %q into %T", test, v)
+		actual := reflect.New(reflect.TypeOf(v))
+		err := bencode.Unmarshal([]byte(test), actual.Interface())
+		if !errors.Is(err, bencode.ErrInvalidType) && !errors.Is(err, bencode.ErrUnsupportedType) {
+			t.Fatalf("Expected a type error, but got (%v, %v)", actual.Elem().Interface(), err)
+		}
+	}
+	for _, invalid := range invalidParserInputs {
+		var v interface{}
+		if err := bencode.Unmarshal([]byte(invalid), &v); err == nil {
+			t.Fatalf("Expected invalid %q to fail.\nInstead got %v", invalid, v)
+		}
+	}
+	// Test invalid targets
+	var v int
+	if err := bencode.Unmarshal([]byte("i1e"), v); err != bencode.ErrInvalidUnmarshal {
+		t.Fatalf("Expected ErrInvalidUnmarshal for non-pointer, but got %v", err)
+	}
+	if err := bencode.Unmarshal([]byte("i1e"), (*int)(nil)); err != bencode.ErrInvalidUnmarshal {
+		t.Fatalf("Expected ErrInvalidUnmarshal for nil pointer, but got %v", err)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	encoded, err := bencode.Marshal(debianTorrentStruct)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	var decoded testTorrent
+	if err := bencode.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, debianTorrentStruct) {
+		t.Fatalf("Expected %v, but got %v", debianTorrentStruct, decoded)
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	data := []byte(debianTorrentEncoded)
+	for i := 0; i < b.N; i++ {
+		var v testTorrent
+		bencode.Unmarshal(data, &v)
+	}
+}